@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+)
+
+const (
+	dialTimeout = 30 * time.Second
+	// reconnect delays double from minBackoff up to maxBackoff, a session
+	// that stayed up longer than maxBackoff starts over from minBackoff
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// agent runs captures on behalf of the collector as told on the Control
+// stream. The capture it was told to run survives reconnects, packets
+// captured while disconnected go to the spool.
+type agent struct {
+	client  service.RemoteCaputreClient
+	session *service.ReadyReply
+	stream  *controlStream
+	ctx     context.Context
+	cancel  context.CancelFunc
+	streams sync.WaitGroup
+	capture *capture
+
+	deviceNames []string
+	filter      string
+	paused      bool
+}
+
+// runAgent keeps a control session with the collector open, reconnecting with
+// exponential backoff whenever dialing, registering or a stream fails. It
+// captures on deviceNames right away when there are any.
+func runAgent(deviceNames []string, filter string) {
+	a := &agent{deviceNames: deviceNames, filter: filter}
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := a.connect()
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		fmt.Printf("connection to collector lost: %v, reconnecting in %s\n", err, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect runs one control session, it always returns the error that ended it
+func (a *agent) connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	conn, err := dialCollector(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("can not connect with server %v", err)
+	}
+	defer conn.Close()
+
+	a.client = service.NewRemoteCaputreClient(conn)
+	var running *capture
+	if a.capture != nil && !a.capture.Stopped() {
+		running = a.capture
+	}
+	a.session, err = register(a.client, localIP(collectorHost()), running)
+	if err != nil {
+		return fmt.Errorf("can not register with server %v", err)
+	}
+
+	a.ctx, a.cancel = context.WithCancel(sessionContext(context.Background(), a.session))
+	defer a.streams.Wait()
+	defer a.cancel()
+	stream, err := a.client.Control(a.ctx)
+	if err != nil {
+		return fmt.Errorf("open control stream error %v", err)
+	}
+	a.stream = &controlStream{RemoteCaputre_ControlClient: stream}
+	fmt.Println("Connected, waiting for commands (CTRL + C) to abort")
+
+	// pick up the capture that was running before the connection dropped
+	if running != nil {
+		a.streamCapture()
+	} else if len(a.deviceNames) > 0 {
+		if err := a.startCapture(); err != nil {
+			a.stream.acknowledge(0, err)
+		}
+	}
+
+	for {
+		cmd, err := a.stream.Recv()
+		if err != nil {
+			return err
+		}
+		verbosePrint(fmt.Sprintf("command %d: %s %s %s", cmd.ID, cmd.Type, cmd.Interface, cmd.Filter))
+		if err := a.stream.acknowledge(cmd.ID, a.execute(cmd)); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *agent) execute(cmd *service.Command) error {
+	switch cmd.Type {
+	case service.CommandType_NOOP:
+		return nil
+	case service.CommandType_START:
+		deviceNames, err := devicesByList(cmd.Interface)
+		if err != nil {
+			return err
+		}
+		a.stopCapture()
+		a.deviceNames, a.filter, a.paused = deviceNames, cmd.Filter, false
+		return a.startCapture()
+	}
+
+	if a.capture == nil {
+		return fmt.Errorf("no capture running")
+	}
+	switch cmd.Type {
+	case service.CommandType_STOP:
+		a.deviceNames = nil
+		a.stopCapture()
+	case service.CommandType_PAUSE:
+		a.paused = true
+		a.capture.Pause()
+	case service.CommandType_RESUME:
+		a.paused = false
+		a.capture.Resume()
+	case service.CommandType_SET_FILTER:
+		if err := a.capture.SetFilter(cmd.Filter); err != nil {
+			return err
+		}
+		a.filter = cmd.Filter
+		// traces opened from now on record the new filter
+		if err := describe(a.client, a.session, localIP(collectorHost()), a.capture); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown command %s", cmd.Type)
+	}
+	return nil
+}
+
+// startCapture starts the capture the agent was told to run
+func (a *agent) startCapture() error {
+	c, err := startCapture(a.deviceNames, a.filter)
+	if err != nil {
+		return err
+	}
+	if a.paused {
+		c.Pause()
+	}
+	if err := describe(a.client, a.session, localIP(collectorHost()), c); err != nil {
+		c.Stop()
+		return fmt.Errorf("can not describe capture to server %v", err)
+	}
+	a.capture = c
+	a.streamCapture()
+	fmt.Printf("Streaming Packets from %s\n", c)
+	return nil
+}
+
+// streamCapture forwards the running capture for the rest of the session. A
+// failing stream is reported to the collector and ends the session, so the
+// capture resumes after reconnecting.
+func (a *agent) streamCapture() {
+	c, client, session := a.capture, a.client, a.session
+	ctx, endSession, stream := a.ctx, a.cancel, a.stream
+	a.streams.Add(1)
+	go func() {
+		defer a.streams.Done()
+		err := c.Stream(ctx, client, session)
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("capture on %s stopped: %v\n", c, err)
+			stream.acknowledge(0, fmt.Errorf("capture on %s stopped: %v", c, err))
+			endSession()
+		}
+	}()
+}
+
+// stopCapture stops the running capture, if any
+func (a *agent) stopCapture() {
+	if a.capture == nil {
+		return
+	}
+	a.capture.Stop()
+	a.capture = nil
+}
+
+// controlStream serializes replies sent from the command loop and capture streams
+type controlStream struct {
+	mu sync.Mutex
+	service.RemoteCaputre_ControlClient
+}
+
+// acknowledge reports the outcome of command id, id 0 reports an error outside of a command
+func (s *controlStream) acknowledge(id uint64, err error) error {
+	r := &service.CommandReply{ID: id, Ok: err == nil}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Send(r)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"google.golang.org/protobuf/proto"
+)
+
+// batcher groups packets into PacketBatch frames. A batch is due once it holds
+// maxCount packets or maxBytes of encoded packets, partial batches are flushed
+// by the caller every -batchms.
+type batcher struct {
+	maxCount int
+	maxBytes int
+	sequence uint64
+	packets  []*service.Packet
+	size     int
+}
+
+func newBatcher(maxCount, maxBytes int) *batcher {
+	return &batcher{
+		maxCount: maxCount,
+		maxBytes: maxBytes,
+		packets:  make([]*service.Packet, 0, maxCount),
+	}
+}
+
+// add queues pkt and reports whether the batch is full
+func (b *batcher) add(pkt *service.Packet) bool {
+	b.packets = append(b.packets, pkt)
+	b.size += proto.Size(pkt)
+	return len(b.packets) >= b.maxCount || b.size >= b.maxBytes
+}
+
+// take returns the queued packets as the next batch and starts a new one
+func (b *batcher) take() *service.PacketBatch {
+	b.sequence++
+	batch := &service.PacketBatch{Sequence: b.sequence, Packets: b.packets}
+	b.packets = make([]*service.Packet, 0, b.maxCount)
+	b.size = 0
+	return batch
+}
+
+// batchSender sends packets in PacketBatch frames on a CaptureBatch stream
+type batchSender struct {
+	stream service.RemoteCaputre_CaptureBatchClient
+	b      *batcher
+	unsent []*service.Packet
+}
+
+func (s *batchSender) Send(pkt *service.Packet) error {
+	if !s.b.add(pkt) {
+		return nil
+	}
+	return s.Flush()
+}
+
+func (s *batchSender) SendAll(pkts []*service.Packet) error {
+	for _, pkt := range pkts {
+		s.b.add(pkt)
+	}
+	return s.Flush()
+}
+
+func (s *batchSender) Flush() error {
+	if len(s.b.packets) == 0 {
+		return nil
+	}
+	batch := s.b.take()
+	if err := s.stream.Send(batch); err != nil {
+		s.unsent = batch.Packets
+		return sendError(err, s.stream.CloseAndRecv)
+	}
+	return nil
+}
+
+func (s *batchSender) Unsent() []*service.Packet {
+	return append(s.unsent, s.b.packets...)
+}
+
+func (s *batchSender) Close() (*service.CaptureSummary, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	return streamError(s.stream.CloseAndRecv())
+}
@@ -0,0 +1,613 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+	"google.golang.org/grpc/metadata"
+)
+
+// capture forwards the packets of one or more interfaces to the collector over
+// a Capture or CaptureBatch stream, whichever GetReady negotiated. Packets are
+// tagged with the index of their interface in deviceNames. The capture
+// outlives the streams, so a reconnecting agent picks up where the last one
+// failed.
+type capture struct {
+	// first for 64-bit atomic alignment on 32-bit platforms
+	dropped uint64
+	count   int64
+	bytes   int64
+
+	deviceNames []string
+	filter      string
+	requested   string // the filter asked for, filter adds what is always required
+	handles     []*pcap.Handle
+	captured    []int64
+	packets     chan *service.Packet
+	spool       *spool
+	paused      int32
+	stopOnce    sync.Once
+	stop        chan struct{}
+	runs        sync.WaitGroup
+	ran         chan struct{}
+	streamMu    sync.Mutex
+	offline     chan struct{}
+	spooled     chan struct{}
+	statsMu     sync.Mutex
+	stats       chan []*service.CaptureStats
+}
+
+// packetSequence is the sequence number of the last captured packet. It is
+// shared by all captures of the process, so numbers keep increasing across
+// captures and reconnects.
+var packetSequence uint64
+
+// instance tells the collector which sessions share packetSequence, so it
+// finds packets lost across reconnects. With a spool it is kept across
+// restarts as long as spooled packets are numbered by it.
+var instance = newInstance()
+
+func newInstance() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// sessionContext attaches the session of a GetReady reply to ctx
+func sessionContext(ctx context.Context, reply *service.ReadyReply) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, service.SessionMetadataKey, reply.GetSessionID())
+}
+
+// register announces this endpoint and its capture c, nil before one runs, to
+// the collector and negotiates the stream format
+func register(client service.RemoteCaputreClient, IP string, c *capture) (*service.ReadyReply, error) {
+	reply, err := getReady(context.Background(), client, IP, c)
+	if err != nil {
+		return nil, err
+	}
+	if reply.GetCredential() != "" {
+		if err := credential.Save(reply.GetCredential()); err != nil {
+			return nil, fmt.Errorf("can not keep the agent credential: %v", err)
+		}
+		fmt.Printf("Enrolled, credential saved to %s\n", *credentialFile)
+	}
+	// servers that don't know about typed capture info answer LEGACY_JSON
+	// and never agree to batching
+	verbosePrint(fmt.Sprintf("Capture info format: %s", reply.GetCaptureInfoFormat()))
+	fmt.Printf("Session %s, collector version %s\n", reply.GetSessionID(), serverVersion(reply))
+	if err := applySession(client, reply, IP, c); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func serverVersion(reply *service.ReadyReply) string {
+	if reply.GetServerVersion() == "" {
+		return "unknown"
+	}
+	return reply.GetServerVersion()
+}
+
+// applySession adopts the limits of the session of reply: captures started
+// from now on capture no more than its snaplen, and all captures, c as well
+// if running, apply its required filters
+func applySession(client service.RemoteCaputreClient, reply *service.ReadyReply, IP string, c *capture) error {
+	if n := reply.GetSnaplen(); n > 0 && int64(n) < int64(snapshotLen) {
+		verbosePrint(fmt.Sprintf("Collector stores up to %d bytes of a packet", n))
+		snapshotLen = int32(n)
+	}
+	if reply.GetMaxRate() > 0 {
+		verbosePrint(fmt.Sprintf("Sending at most %d bytes per second", reply.GetMaxRate()))
+	}
+	required := andFilters(reply.GetRequiredFilters()...)
+	if required == requiredFilter {
+		return nil
+	}
+	requiredFilter = required
+	if required != "" {
+		fmt.Printf("Collector requires filter: %s\n", required)
+	}
+	if c == nil {
+		return nil
+	}
+	if err := c.SetFilter(c.requested); err != nil {
+		return fmt.Errorf("can not apply the required filter: %v", err)
+	}
+	// the collector recorded the filter c had before
+	return describe(client, reply, IP, c)
+}
+
+// describe tells the collector about the capture c that the session of reply streams next
+func describe(client service.RemoteCaputreClient, reply *service.ReadyReply, IP string, c *capture) error {
+	_, err := getReady(sessionContext(context.Background(), reply), client, IP, c)
+	return err
+}
+
+func getReady(ctx context.Context, client service.RemoteCaputreClient, IP string, c *capture) (*service.ReadyReply, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	hostname, _ := os.Hostname()
+	e := service.EndpointInfo{
+		IPaddress:         IP,
+		Hostname:          hostname,
+		OS:                runtime.GOOS,
+		CaptureInfoFormat: service.CaptureInfoFormat_TYPED,
+		Batching:          *batchCount > 1,
+		EnrollmentToken:   credential.token(),
+		Instance:          instance,
+	}
+	if c != nil {
+		for i, handle := range c.handles {
+			e.Interfaces = append(e.Interfaces, &service.CaptureInterface{
+				Name:     c.deviceNames[i],
+				LinkType: int32(handle.LinkType()),
+				Snaplen:  uint32(handle.SnapLen()),
+				Filter:   c.filter,
+			})
+		}
+		first := e.Interfaces[0]
+		e.Interface, e.LinkType, e.Snaplen, e.Filter = first.Name, first.LinkType, first.Snaplen, first.Filter
+	}
+	return client.GetReady(ctx, &e)
+}
+
+// startCapture opens deviceNames with filter. Devices that can't be opened
+// or don't take the filter are skipped, as long as one of them can capture.
+// Packets queue up until Stream forwards them to the collector and are
+// spooled, or dropped when there is no spool, while the capture is offline
+// between streams.
+func startCapture(deviceNames []string, filter string) (*capture, error) {
+	c := &capture{
+		packets: make(chan *service.Packet, 500),
+		spool:   packetSpool,
+		stop:    make(chan struct{}),
+		ran:     make(chan struct{}),
+		stats:   make(chan []*service.CaptureStats, 1),
+	}
+	bpf := andFilters(whitelistFilter, requiredFilter, filter)
+	verbosePrint(bpf)
+	var failed []string
+	for _, deviceName := range deviceNames {
+		handle, err := openDevice(deviceName, bpf)
+		if err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		c.handles = append(c.handles, handle)
+		c.deviceNames = append(c.deviceNames, deviceName)
+	}
+	if len(c.handles) == 0 {
+		return nil, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+	for _, f := range failed {
+		fmt.Printf("Skipping %s\n", f)
+	}
+	c.captured = make([]int64, len(c.handles))
+	c.filter, c.requested = bpf, filter
+	for i, handle := range c.handles {
+		c.runs.Add(1)
+		go c.run(i, gopacket.NewPacketSource(handle, handle.LinkType()))
+	}
+	if *statsInterval > 0 {
+		c.runs.Add(1)
+		go c.sampleEvery(time.Duration(*statsInterval) * time.Second)
+	}
+	go func() {
+		c.runs.Wait()
+		close(c.ran)
+	}()
+	return c, nil
+}
+
+// String lists the interfaces of the capture
+func (c *capture) String() string {
+	return strings.Join(c.deviceNames, ", ")
+}
+
+// openDevice opens deviceName for capturing packets matching bpf
+func openDevice(deviceName string, bpf string) (*pcap.Handle, error) {
+	handle, err := pcap.OpenLive(deviceName, snapshotLen, promiscuous, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening device %s: %v", deviceName, err)
+	}
+	if err := handle.SetBPFFilter(bpf); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("%s: %v", deviceName, err)
+	}
+	return handle, nil
+}
+
+func (c *capture) closeHandles() {
+	for _, handle := range c.handles {
+		handle.Close()
+	}
+}
+
+// andFilters joins the non-empty BPF filters so that packets must match all of them
+func andFilters(filters ...string) string {
+	var parts []string
+	for _, f := range filters {
+		if f != "" {
+			parts = append(parts, f)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i := range parts {
+		parts[i] = "(" + parts[i] + ")"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// SetFilter replaces the capture filter on all interfaces, the collector's own
+// traffic and any whitelisted hosts always stay excluded and the filters the
+// collector requires always apply. A filter that fails on one of the
+// interfaces is taken back from the others.
+func (c *capture) SetFilter(filter string) error {
+	bpf := andFilters(whitelistFilter, requiredFilter, filter)
+	verbosePrint(bpf)
+	for i, handle := range c.handles {
+		if err := handle.SetBPFFilter(bpf); err != nil {
+			if c.filter != "" {
+				for _, set := range c.handles[:i] {
+					set.SetBPFFilter(c.filter)
+				}
+			}
+			return fmt.Errorf("%s: %v", c.deviceNames[i], err)
+		}
+	}
+	c.filter, c.requested = bpf, filter
+	return nil
+}
+
+// Pause drops captured packets until Resume is called
+func (c *capture) Pause() {
+	atomic.StoreInt32(&c.paused, 1)
+}
+
+func (c *capture) Resume() {
+	atomic.StoreInt32(&c.paused, 0)
+}
+
+// Stopped reports whether the capture was stopped or hit one of its limits
+func (c *capture) Stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop ends the capture, a running Stream sends the packets captured so far
+// before it returns, otherwise they go to the spool
+func (c *capture) Stop() {
+	c.halt()
+	<-c.ran
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	c.stopOffline()
+	c.offlineWrite()
+	c.closeHandles()
+}
+
+func (c *capture) halt() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Stream forwards the capture on a new stream of the session of reply, after
+// replaying the spool. It returns nil once the capture stopped and everything
+// was sent, or the error that ended the stream, after which the capture goes
+// offline until the next call.
+func (c *capture) Stream(ctx context.Context, client service.RemoteCaputreClient, reply *service.ReadyReply) error {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	sender, err := openSender(ctx, client, reply)
+	if err != nil {
+		c.goOffline()
+		return err
+	}
+	if err := c.replay(sender); err != nil {
+		c.goOffline()
+		return err
+	}
+	if dropped := atomic.SwapUint64(&c.dropped, 0); dropped > 0 {
+		fmt.Printf("%d packets dropped while offline\n", dropped)
+	}
+
+	flush := time.NewTicker(time.Duration(*batchDelay) * time.Millisecond)
+	defer flush.Stop()
+	for {
+		select {
+		case pkt := <-c.packets:
+			err = sender.Send(pkt)
+		case <-flush.C:
+			err = sender.Flush()
+		case stats := <-c.stats:
+			go reportStats(ctx, client, reply, stats)
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-c.stop:
+			<-c.ran
+			for {
+				select {
+				case pkt := <-c.packets:
+					if err := sender.Send(pkt); err != nil {
+						c.offlineWrite(sender.Unsent()...)
+						return err
+					}
+					continue
+				default:
+				}
+				break
+			}
+			summary, err := sender.Close()
+			if err != nil {
+				c.offlineWrite(sender.Unsent()...)
+				return err
+			}
+			if summary != nil {
+				printSummary(summary)
+			}
+			c.sampleStats()
+			select {
+			case stats := <-c.stats:
+				reportStats(ctx, client, reply, stats)
+			default:
+			}
+			return nil
+		}
+		if err != nil {
+			c.offlineWrite(sender.Unsent()...)
+			c.goOffline()
+			return err
+		}
+	}
+}
+
+// replay sends the spool on sender. The spooler keeps draining the capture
+// meanwhile and is stopped once the spool ran empty, so that no packet
+// overtakes an older one.
+func (c *capture) replay(sender packetSender) error {
+	if c.spool == nil {
+		c.stopOffline()
+		return nil
+	}
+	replayed := 0
+	for {
+		pkts, mark, err := c.spool.Peek(*batchCount, *batchBytes)
+		if err != nil {
+			return err
+		}
+		if len(pkts) == 0 {
+			if c.offline == nil {
+				break
+			}
+			// the spooler may have written more until it stopped
+			c.stopOffline()
+			continue
+		}
+		if err := sender.SendAll(pkts); err != nil {
+			return err
+		}
+		c.spool.Discard(mark)
+		replayed += len(pkts)
+	}
+	if replayed > 0 {
+		fmt.Printf("%d spooled packets replayed\n", replayed)
+	}
+	if dropped := c.spool.Dropped(); dropped > 0 {
+		fmt.Printf("%d packets dropped by the full spool so far\n", dropped)
+	}
+	return nil
+}
+
+// goOffline spools or drops the packets of the capture until the next Stream
+func (c *capture) goOffline() {
+	if c.offline != nil || c.Stopped() {
+		return
+	}
+	c.offline = make(chan struct{})
+	c.spooled = make(chan struct{})
+	go func(offline, spooled chan struct{}) {
+		defer close(spooled)
+		for {
+			select {
+			case pkt := <-c.packets:
+				c.offlineWrite(pkt)
+			case <-offline:
+				return
+			case <-c.stop:
+				return
+			}
+		}
+	}(c.offline, c.spooled)
+}
+
+// stopOffline waits for the spooler to finish the packet it is writing
+func (c *capture) stopOffline() {
+	if c.offline == nil {
+		return
+	}
+	close(c.offline)
+	<-c.spooled
+	c.offline, c.spooled = nil, nil
+}
+
+// offlineWrite spools pkts, or everything still queued when called without packets
+func (c *capture) offlineWrite(pkts ...*service.Packet) {
+	if len(pkts) == 0 {
+		for {
+			select {
+			case pkt := <-c.packets:
+				pkts = append(pkts, pkt)
+				continue
+			default:
+			}
+			break
+		}
+	}
+	if c.spool == nil {
+		atomic.AddUint64(&c.dropped, uint64(len(pkts)))
+		return
+	}
+	if err := c.spool.Write(pkts...); err != nil {
+		fmt.Printf("can not spool packets: %v\n", err)
+	}
+}
+
+// sampleStats reads the libpcap counters of all interfaces. Samples are
+// reported by Stream, only the latest one waits while the capture is offline.
+func (c *capture) sampleStats() {
+	var sample []*service.CaptureStats
+	for i, handle := range c.handles {
+		s, err := handle.Stats()
+		if err != nil {
+			verbosePrint(fmt.Sprintf("can not read capture statistics of %s: %v", c.deviceNames[i], err))
+			continue
+		}
+		sample = append(sample, &service.CaptureStats{
+			Interface:        c.deviceNames[i],
+			PacketsReceived:  int64(s.PacketsReceived),
+			PacketsDropped:   int64(s.PacketsDropped),
+			PacketsIfDropped: int64(s.PacketsIfDropped),
+			Captured:         atomic.LoadInt64(&c.captured[i]),
+		})
+		verbosePrint(fmt.Sprintf("%s: %d packets received, %d dropped by the kernel, %d by the interface",
+			c.deviceNames[i], s.PacketsReceived, s.PacketsDropped, s.PacketsIfDropped))
+	}
+	if len(sample) == 0 {
+		return
+	}
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	select {
+	case <-c.stats:
+	default:
+	}
+	c.stats <- sample
+}
+
+// sampleEvery samples the statistics every interval until the capture stops,
+// quiet interfaces report their drops as well
+func (c *capture) sampleEvery(interval time.Duration) {
+	defer c.runs.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.sampleStats()
+		}
+	}
+}
+
+// reportStats sends a statistics sample to the collector, servers that don't
+// know about ReportStats fail the call, which is only worth a verbose message
+func reportStats(ctx context.Context, client service.RemoteCaputreClient, reply *service.ReadyReply, sample []*service.CaptureStats) {
+	ctx, cancel := context.WithTimeout(sessionContext(ctx, reply), 10*time.Second)
+	defer cancel()
+	for _, stats := range sample {
+		if _, err := client.ReportStats(ctx, stats); err != nil {
+			verbosePrint(fmt.Sprintf("can not report capture statistics: %v", err))
+			return
+		}
+	}
+}
+
+// run converts captured packets to their wire format and queues them for sending
+func (c *capture) run(index int, src *gopacket.PacketSource) {
+	defer c.runs.Done()
+	packets := src.Packets()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case packet, ok := <-packets:
+			if !ok {
+				c.halt()
+				return
+			}
+			if atomic.LoadInt32(&c.paused) == 1 {
+				continue
+			}
+			if packet.NetworkLayer() == nil || packet.TransportLayer() == nil {
+				//verbosePrint("Unusable packet")
+				continue
+			}
+			count := atomic.AddInt64(&c.count, 1)
+			atomic.AddInt64(&c.captured[index], 1)
+			data := packet.Data()
+			bytes := atomic.AddInt64(&c.bytes, int64(len(data)))
+			if *verbose {
+				if count%100 == 0 {
+					fmt.Printf("sent #%d packets\n", count)
+				}
+
+			}
+			if *statsevery > 0 && count%int64(*statsevery) == 0 {
+				c.sampleStats()
+			}
+			if *dumpOption {
+				fmt.Printf("Packet content (%d/0x%x)\n%s\n", len(data), len(data), hex.Dump(data))
+			}
+
+			pkt := &service.Packet{
+				Data: data,
+				Info: service.NewCaptureInfo(packet.Metadata().CaptureInfo),
+			}
+			pkt.Info.InterfaceIndex = int32(index)
+
+			if *maxcount != 0 && count >= int64(*maxcount) {
+				fmt.Printf("\nExiting ...")
+				fmt.Printf("\nCaptured %d packets \n", count)
+				c.halt()
+				return
+			}
+
+			if *maxbytes != 0 && bytes >= int64(*maxbytes) {
+				fmt.Printf("\nExiting ...")
+				fmt.Printf("\nPacket Size %d bytes \n", bytes)
+				c.halt()
+				return
+			}
+
+			pkt.Sequence = atomic.AddUint64(&packetSequence, 1)
+			select {
+			case c.packets <- pkt:
+			case <-c.stop:
+				return
+			}
+		}
+	}
+}
+
+// printSummary logs what the collector stored of a stream
+func printSummary(summary *service.CaptureSummary) {
+	fmt.Printf("Collector stored %d packets, %d bytes", summary.GetPackets(), summary.GetBytes())
+	if summary.GetGaps() > 0 {
+		fmt.Printf(", %d packets went missing in %d gaps", summary.GetMissing(), summary.GetGaps())
+	}
+	fmt.Println()
+	for _, file := range summary.GetFiles() {
+		fmt.Printf("  %s\n", file)
+	}
+}
@@ -3,11 +3,8 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/hex"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net"
 	"net/http"
@@ -19,46 +16,61 @@ import (
 	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
 
 	valid "github.com/asaskevich/govalidator"
-	"github.com/google/gopacket"
 	"github.com/google/gopacket/pcap"
-	"google.golang.org/grpc"
 )
 
 var (
-	deviceName       string = ""
-	snapshotLen      int32  = 65535
-	promiscuous      bool   = false
+	deviceNames      []string
+	snapshotLen      int32 = 65535
+	promiscuous      bool  = false
 	err              error
-	timeout          = pcap.BlockForever
-	handle           *pcap.Handle
+	timeout                 = pcap.BlockForever
 	OS               string = ""
 	done             bool   = false // for signaling ctrl+c
 	errorsMap        map[string]uint
 	errorsMapMutex   sync.Mutex
 	errors           uint
-	count            int = 0
-	bytes            int = 0
-	sendchan             = make(chan service.Packet, 500)
 	whitelistedHosts []string
 	whitelistFilter  string
+	requiredFilter   string // filters the collector requires
+	packetSpool      *spool
+	credential       *agentCredential
 )
 
 //Flag options
 
-var networkCard = flag.Int("interface", 0, "try -listNIC before")
+var networkCard = flag.String("interface", "", "Interface by -listNIC number, name, IP, CIDR, /regexp/, description or default, several as 2,5 or all")
 var snaplen = flag.Int("snaplen", 0, "Max bytes to capture")
-var serverIP = flag.String("remote", "127.0.0.1", "Remote Packet Collector IP")
+var serverIP = flag.String("remote", "127.0.0.1", "Remote Packet Collector: host, host:port, [IPv6]:port or unix:/path/to/socket")
+var exceptionsURL = flag.String("exceptions", "", "URL of the whitelist, default: exceptions.list on port 8080 of the -remote host")
 var dumpOption = flag.Bool("dumppkt", false, "Dump packet")
 var listNICsOption = flag.Bool("listNIC", false, "list network cards")
+var listFormat = flag.String("format", "text", "Output format of -listNIC: text or json")
 var captureFilter = flag.String("filter", "", "Capture filter")
 var resolveExceptions = flag.Bool("resolve", false, "Resolve whitelisted domains")
 var promisc = flag.Bool("promisc", false, "Set promiscuous mode")
 var maxcount = flag.Int("count", 0, "Only grab this number packets, then exit")
 var maxbytes = flag.Int("bytes", 0, "Only grab this number bytes, then exit")
-var statsevery = flag.Int("stats", 1000, "Output statistics every N packets")
+var statsevery = flag.Int("stats", 1000, "Report capture statistics to the collector every N packets, 0 disables them")
+var statsInterval = flag.Int("statsinterval", 60, "Also report capture statistics to the collector every N seconds, 0 disables it")
 var verbose = flag.Bool("verbose", false, "Verbose output")
 var whitelisting = flag.Bool("whitelist", false, "Use whitelists, default: IP Address only, use resolve for domains")
 var timer = flag.Int("seconds", 0, "Exit after specified seconds")
+var agentMode = flag.Bool("agent", false, "Run as a service taking capture commands from the collector, reconnect whenever the connection drops")
+var batchCount = flag.Int("batch", 64, "Max packets per frame sent to the collector, 1 disables batching")
+var batchBytes = flag.Int("batchbytes", 1<<20, "Flush a batch once it holds this many bytes")
+var batchDelay = flag.Int("batchms", 100, "Flush a partial batch after this many milliseconds")
+var spoolDir = flag.String("spool", "", "Queue packets in this directory while the collector is unreachable")
+var spoolSize = flag.Int("spoolsize", 1024, "Max spool size in MB")
+var spoolEvict = flag.String("spoolevict", "oldest", "What to drop when the spool is full: oldest or newest packets")
+var useTLS = flag.Bool("tls", false, "Connect to the collector over TLS, implied by the other -tls flags")
+var tlsCA = flag.String("tlsca", "", "Verify the collector certificate against the CA in this file instead of the system roots")
+var tlsCert = flag.String("tlscert", "", "Client certificate file for mutual TLS, requires -tlskey")
+var tlsKey = flag.String("tlskey", "", "Key file of -tlscert")
+var tlsName = flag.String("tlsname", "", "Name the collector certificate is issued for, if not the -remote host")
+var enrollToken = flag.String("token", "", "Enrollment token to exchange for an agent credential, for collectors requiring enrollment")
+var credentialFile = flag.String("credential", "agent.credential", "File keeping the agent credential issued by the collector")
+var compressor = flag.String("compress", "auto", "Compress packets sent to the collector: auto picks what the collector prefers, none, gzip, zstd or snappy")
 
 // get ip address of network interface by name
 func GetIpByInterface(NetwrokCard string) (string, error) {
@@ -86,24 +98,14 @@ func GetIpByInterface(NetwrokCard string) (string, error) {
 	return "", nil
 }
 
-// return card raw name by number, shown in -l option
-func NICByNumber(opt int) (string, error) {
-
-	count := 0
-	devices, err := pcap.FindAllDevs()
+// localIP returns the address this host uses to reach remote, no packet is sent
+func localIP(remote string) string {
+	conn, err := net.Dial("udp", net.JoinHostPort(remote, defaultPort))
 	if err != nil {
-		log.Fatal(err)
+		return ""
 	}
-
-	for _, device := range devices {
-		count++
-		if count == opt {
-			return device.Name, nil
-		}
-	}
-
-	return "", fmt.Errorf("Interface not found")
-
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String()
 }
 
 func verbosePrint(msg string) {
@@ -112,14 +114,31 @@ func verbosePrint(msg string) {
 	}
 }
 
+// excludeHost leaves the traffic of host out of every capture
+func excludeHost(host string) {
+	if whitelistFilter != "" {
+		whitelistFilter += " and "
+	}
+	whitelistFilter += fmt.Sprintf("not host %s", host)
+}
+
 func buildFilter() {
 	count := 0
-	resp, err := http.Get(fmt.Sprintf("http://%s:8080/exceptions.list", *serverIP))
+	url := *exceptionsURL
+	if url == "" {
+		if collectorHost() == "" {
+			log.Fatalln("-whitelist needs -exceptions when the collector is reached over a unix socket")
+		}
+		url = fmt.Sprintf("http://%s/exceptions.list", net.JoinHostPort(collectorHost(), "8080"))
+	}
+	resp, err := http.Get(url)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	whitelistFilter = fmt.Sprintf("not host %s ", *serverIP)
+	if collectorHost() != "" {
+		excludeHost(collectorHost())
+	}
 
 	scanner := bufio.NewScanner(resp.Body)
 	defer resp.Body.Close()
@@ -142,7 +161,7 @@ func buildFilter() {
 			if *resolveExceptions {
 				ips, _ := r.LookupHost(context.Background(), line)
 				for _, ip := range ips {
-					whitelistFilter += fmt.Sprintf("and not host %s ", ip)
+					excludeHost(ip)
 
 				}
 				time.Sleep(50 * time.Millisecond)
@@ -151,17 +170,36 @@ func buildFilter() {
 			}
 
 		} else {
-			whitelistFilter += fmt.Sprintf("and not host %s ", line)
+			excludeHost(line)
 			count++
 		}
 	}
 
-	whitelistFilter += *captureFilter
-
 	verbosePrint(fmt.Sprintf("Number of hosts whitelisted: %d", count))
 
 }
 
+// checkBatchFlags rejects batch limits that would never let a batch go out
+func checkBatchFlags() error {
+	switch {
+	case *batchCount < 1:
+		return fmt.Errorf("-batch must be at least 1")
+	case *batchBytes < 1:
+		return fmt.Errorf("-batchbytes must be positive")
+	case *batchDelay < 1:
+		return fmt.Errorf("-batchms must be positive")
+	}
+	return nil
+}
+
+// checkSpoolFlags rejects a spool that could never hold a packet
+func checkSpoolFlags() error {
+	if *spoolSize < 1 {
+		return fmt.Errorf("-spoolsize must be positive")
+	}
+	return nil
+}
+
 func main() {
 
 	if runtime.GOOS == "windows" {
@@ -172,12 +210,29 @@ func main() {
 
 	flag.Parse()
 
-	if len(os.Args) < 3 {
+	if len(os.Args) < 3 && !*agentMode {
 		flag.Usage()
 		os.Exit(0)
 	}
 
+	if err := checkBatchFlags(); err != nil {
+		log.Fatal(err)
+	}
+	if err := checkSpoolFlags(); err != nil {
+		log.Fatal(err)
+	}
+
 	if *listNICsOption {
+		switch *listFormat {
+		case "json":
+			if err := printNICsJSON(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(0)
+		case "text":
+		default:
+			log.Fatalf("unknown -listNIC format %q", *listFormat)
+		}
 		count := 0
 		devices, err := pcap.FindAllDevs()
 		if err != nil {
@@ -200,151 +255,104 @@ func main() {
 		}
 	}
 
-	if *networkCard > 0 {
-		if *snaplen != 0 {
-			snapshotLen = int32(*snaplen)
-		}
-		deviceName, err = NICByNumber(*networkCard)
+	if *snaplen != 0 {
+		snapshotLen = int32(*snaplen)
+	}
+	if *promisc {
+		promiscuous = true
+	}
+
+	// filter unwanted traffic using whitelisting, -filter is added on top of it
+	if *whitelisting {
+		buildFilter()
+	} else if collectorHost() != "" {
+		excludeHost(collectorHost())
+	}
+
+	if err := checkCompressorFlag(); err != nil {
+		log.Fatal(err)
+	}
+
+	credential, err = loadCredential(*credentialFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := checkCredentialTransport(); err != nil {
+		log.Fatal(err)
+	}
 
-		if *promisc {
-			promiscuous = true
+	if *spoolDir != "" {
+		packetSpool, err = openSpool(*spoolDir, int64(*spoolSize)<<20, *spoolEvict)
+		if err != nil {
+			log.Fatal(err)
 		}
-		handle, err = pcap.OpenLive(deviceName, snapshotLen, promiscuous, timeout)
+		packetSequence, err = packetSpool.LastSequence()
 		if err != nil {
-			fmt.Printf("Error opening device %s: %v", deviceName, err)
-			os.Exit(1)
+			log.Fatal(err)
 		}
+		instance = packetSpool.Instance()
+	}
 
-		// filter unwanted traffic using whitelisting or capture filters
-
-		if *whitelisting || *captureFilter != "" {
-			buildFilter()
-			verbosePrint(whitelistFilter)
-			if err := handle.SetBPFFilter(whitelistFilter); err != nil {
-				log.Fatal(err)
-			}
-
-		} else {
-			fmt.Println(*captureFilter)
-			whitelistFilter = fmt.Sprintf("not host %s", *serverIP)
-			verbosePrint(whitelistFilter)
-			if err := handle.SetBPFFilter(whitelistFilter); err != nil {
+	if *agentMode {
+		// with -interface the agent streams right away, otherwise it waits for a start command
+		if *networkCard != "" {
+			deviceNames, err = devicesByList(*networkCard)
+			if err != nil {
 				log.Fatal(err)
 			}
-
 		}
+		runAgent(deviceNames, *captureFilter)
 
-		defer handle.Close()
-		packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
-
+	} else if *networkCard != "" {
+		deviceNames, err = devicesByList(*networkCard)
 		if err != nil {
-			panic("No interface found")
+			log.Fatal(err)
 		}
-		conn, err := grpc.Dial(*serverIP+":9000", grpc.WithInsecure(), grpc.WithBlock())
+
+		conn, err := dialCollector(context.Background())
 		if err != nil {
 			log.Fatalf("can not connect with server %v", err)
 		}
 		defer conn.Close()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-		defer cancel()
-
 		// create gRPC client
 		client := service.NewRemoteCaputreClient(conn)
 
-		hostname, _ := os.Hostname()
-		IP, err := GetIpByInterface(deviceName)
-		if err != nil {
-			fmt.Println(err.Error())
-			os.Exit(1)
+		// several interfaces are told apart by the address the collector is reached from
+		IP := localIP(collectorHost())
+		if len(deviceNames) == 1 {
+			IP, err = GetIpByInterface(deviceNames[0])
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
 		}
 
-		e := service.EndpointInfo{IPaddress: IP, Hostname: hostname, Interface: deviceName}
-		_, err = client.GetReady(ctx, &e)
+		c, err := startCapture(deviceNames, *captureFilter)
 		if err != nil {
 			fmt.Println(err)
+			os.Exit(1)
 		}
 
-		ServerStream, err := client.Capture(context.Background())
+		reply, err := register(client, IP, c)
 		if err != nil {
-			log.Fatalf("open stream error %v", err)
+			c.Stop()
+			log.Fatalf("can not register with server %v", err)
 		}
-
-		go func() {
-			for {
-				select {
-				case pkt := <-sendchan:
-					err = ServerStream.Send(&pkt)
-					if err == io.EOF {
-						fmt.Printf("\nReceived EOF: %v\n", err)
-
-					} else if err != nil {
-						log.Fatalf("can not send %v", err)
-					}
-				}
-			}
-		}()
-
-		packets := packetSource.Packets()
 		fmt.Println("Streaming Packets (CTRL + C) to abort")
 
-		// // timer
-		timeMan := time.NewTimer(time.Duration(*timer) * time.Second)
-
-		for {
-
-			select {
-			case packet := <-packets:
-				if packet.NetworkLayer() == nil || packet.TransportLayer() == nil {
-					//verbosePrint("Unusable packet")
-					continue
-				}
-				count++
-				data := packet.Data()
-				bytes += len(data)
-				if *verbose {
-					if count%100 == 0 {
-						fmt.Printf("sent #%d packets\n", count)
-					}
-
-				}
-				if *dumpOption {
-					fmt.Printf("Packet content (%d/0x%x)\n%s\n", len(data), len(data), hex.Dump(data))
-				}
-
-				byteArray, err := json.Marshal(packet.Metadata())
-				if err != nil {
-					fmt.Println(err)
-				}
-				pkt := service.Packet{
-					Data:                  data,
-					Seralizedcapturreinfo: byteArray,
-				}
-
-				//fmt.Println("debug ", *maxcount)
-				if *maxcount != 0 && count >= *maxcount {
-					fmt.Printf("\nExiting ...")
-					fmt.Printf("\nCaptured %d packets \n", count)
-					os.Exit(0)
-				}
-
-				if *maxbytes != 0 && bytes >= *maxbytes {
-					fmt.Printf("\nExiting ...")
-					fmt.Printf("\nPacket Size %d bytes \n", bytes)
-					os.Exit(0)
-				}
-				sendchan <- pkt
-			}
-			if *timer != 0 {
-				go func() {
-					select {
-					case <-timeMan.C:
-						fmt.Println("Time up, exiting")
-						os.Exit(0)
-					}
-				}()
+		if *timer != 0 {
+			go func() {
+				<-time.After(time.Duration(*timer) * time.Second)
+				fmt.Println("Time up, exiting")
+				c.Stop()
+			}()
+		}
 
-			}
+		err = c.Stream(context.Background(), client, reply)
+		c.Stop()
+		if err != nil {
+			log.Fatalf("can not send %v", err)
 		}
 
 	} else {
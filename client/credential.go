@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+)
+
+// agentCredential is sent as metadata on every call to the collector once it
+// issued one in exchange for -token, it is kept in the -credential file
+type agentCredential struct {
+	mu    sync.RWMutex
+	path  string
+	value string
+}
+
+// loadCredential reads the credential kept at path, there is none before enrolling
+func loadCredential(path string) (*agentCredential, error) {
+	c := &agentCredential{path: path}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	c.value = strings.TrimSpace(string(data))
+	return c, nil
+}
+
+// Save keeps the credential issued by the collector for later calls and runs
+func (c *agentCredential) Save(value string) error {
+	if err := ioutil.WriteFile(c.path, []byte(value+"\n"), 0600); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.value = value
+	c.mu.Unlock()
+	return nil
+}
+
+// Enrolled reports whether the collector issued a credential
+func (c *agentCredential) Enrolled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.value != ""
+}
+
+// token returns the -token to send in GetReady, only until a credential was
+// issued. A revoked agent keeps failing until its credential file is removed.
+func (c *agentCredential) token() string {
+	if c != nil && c.Enrolled() {
+		return ""
+	}
+	return *enrollToken
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (c *agentCredential) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.value == "" {
+		return nil, nil
+	}
+	return map[string]string{service.CredentialMetadataKey: c.value}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials, the
+// credential never crosses the network in plaintext
+func (c *agentCredential) RequireTransportSecurity() bool {
+	return true
+}
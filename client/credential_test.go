@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialToken(t *testing.T) {
+	defer func(token string) { *enrollToken = token }(*enrollToken)
+	*enrollToken = "id.secret"
+
+	c, err := loadCredential(filepath.Join(t.TempDir(), "agent.credential"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Enrolled() || c.token() != *enrollToken {
+		t.Fatalf("before enrolling: enrolled %v, token %q", c.Enrolled(), c.token())
+	}
+	if err := c.Save("agent.secret"); err != nil {
+		t.Fatal(err)
+	}
+	// a revoked agent must not enroll again by itself
+	if !c.Enrolled() || c.token() != "" {
+		t.Errorf("after enrolling: enrolled %v, token %q", c.Enrolled(), c.token())
+	}
+	c, err = loadCredential(c.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Enrolled() || c.token() != "" {
+		t.Errorf("saved credential: enrolled %v, token %q", c.Enrolled(), c.token())
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// defaultPort is the collector port used when -remote names none
+const defaultPort = "9000"
+
+// collectorTarget returns the address to dial for -remote, unix sockets are
+// passed on to grpc as they are
+func collectorTarget() string {
+	if strings.HasPrefix(*serverIP, "unix:") {
+		return *serverIP
+	}
+	port := defaultPort
+	if _, p, err := net.SplitHostPort(*serverIP); err == nil {
+		port = p
+	}
+	return net.JoinHostPort(collectorHost(), port)
+}
+
+// collectorHost returns the host of -remote, empty for a unix socket
+func collectorHost() string {
+	if strings.HasPrefix(*serverIP, "unix:") {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(*serverIP); err == nil {
+		return host
+	}
+	// a host without port, IPv6 addresses may still be bracketed
+	return strings.TrimSuffix(strings.TrimPrefix(*serverIP, "["), "]")
+}
+
+// dialCollector connects to the collector, over TLS when any of the -tls
+// flags is set. The agent credential is only sent over TLS.
+func dialCollector(ctx context.Context) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{creds, grpc.WithBlock()}
+	if useTLSFlags() {
+		opts = append(opts, grpc.WithPerRPCCredentials(credential))
+	}
+	return grpc.DialContext(ctx, collectorTarget(), opts...)
+}
+
+// useTLSFlags reports whether any of the -tls flags is set
+func useTLSFlags() bool {
+	return *useTLS || *tlsCA != "" || *tlsCert != "" || *tlsKey != ""
+}
+
+// checkCredentialTransport refuses to send a token or credential in plaintext
+func checkCredentialTransport() error {
+	if useTLSFlags() || *enrollToken == "" && !credential.Enrolled() {
+		return nil
+	}
+	return fmt.Errorf("enrollment tokens and agent credentials are only sent over TLS, use -tls")
+}
+
+func transportCredentials() (grpc.DialOption, error) {
+	if !useTLSFlags() {
+		return grpc.WithInsecure(), nil
+	}
+	// an empty ServerName is taken from the dialed address
+	config := &tls.Config{
+		ServerName: *tlsName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if *tlsCA != "" {
+		pool, err := service.LoadCertPool(*tlsCA)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if *tlsCert != "" || *tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+// streamCompressor picks what Capture streams are compressed with by -compress
+// among the compressors the collector accepts, empty to send them as they are
+func streamCompressor(reply *service.ReadyReply) string {
+	accepted := reply.GetCompressors()
+	switch *compressor {
+	case service.CompressorNone:
+		return ""
+	case "auto":
+		for _, name := range accepted {
+			if service.CheckCompressor(name) == nil {
+				return name
+			}
+		}
+		return ""
+	}
+	for _, name := range accepted {
+		if name == *compressor {
+			return name
+		}
+	}
+	fmt.Printf("collector does not accept %s compression, sending uncompressed\n", *compressor)
+	return ""
+}
+
+// checkCompressorFlag rejects a -compress this build can't use
+func checkCompressorFlag() error {
+	if *compressor == "auto" || *compressor == service.CompressorNone {
+		return nil
+	}
+	return service.CheckCompressor(*compressor)
+}
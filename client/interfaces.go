@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket/pcap"
+)
+
+// flags of a pcap_if_t, as defined by libpcap
+const (
+	pcapIfLoopback = 0x1
+	pcapIfUp       = 0x2
+	pcapIfRunning  = 0x4
+	pcapIfWireless = 0x8
+)
+
+// nicInfo describes a card for -listNIC -format json
+type nicInfo struct {
+	Number      int
+	Name        string
+	Description string
+	Addresses   []nicAddress
+	Up          bool
+	Loopback    bool
+	Running     bool
+	Wireless    bool
+	// LinkType is missing when the card could not be opened, Error tells why
+	LinkType     *int   `json:",omitempty"`
+	LinkTypeName string `json:",omitempty"`
+	Error        string `json:",omitempty"`
+}
+
+type nicAddress struct {
+	IP           string
+	Netmask      string `json:",omitempty"`
+	PrefixLength int    `json:",omitempty"`
+}
+
+// printNICsJSON writes every card pcap finds to w as a JSON array, numbered as in -listNIC
+func printNICsJSON(w io.Writer) error {
+	devices, err := pcap.FindAllDevs()
+	if err != nil {
+		return err
+	}
+	nics := make([]nicInfo, 0, len(devices))
+	for i, device := range devices {
+		nic := nicInfo{
+			Number:      i + 1,
+			Name:        device.Name,
+			Description: device.Description,
+			Addresses:   make([]nicAddress, 0, len(device.Addresses)),
+			Up:          device.Flags&pcapIfUp != 0,
+			Loopback:    device.Flags&pcapIfLoopback != 0,
+			Running:     device.Flags&pcapIfRunning != 0,
+			Wireless:    device.Flags&pcapIfWireless != 0,
+		}
+		for _, address := range device.Addresses {
+			a := nicAddress{IP: address.IP.String()}
+			if len(address.Netmask) > 0 {
+				a.Netmask = net.IP(address.Netmask).String()
+				a.PrefixLength, _ = address.Netmask.Size()
+			}
+			nic.Addresses = append(nic.Addresses, a)
+		}
+		// the link type is only known once the card is opened
+		handle, err := pcap.OpenLive(device.Name, 64, false, time.Millisecond)
+		if err != nil {
+			nic.Error = err.Error()
+		} else {
+			linkType := handle.LinkType()
+			number := int(linkType)
+			nic.LinkType, nic.LinkTypeName = &number, linkType.String()
+			handle.Close()
+		}
+		nics = append(nics, nic)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nics)
+}
+
+// defaultRouteProbe is dialed, without sending anything, to find the address
+// of the interface holding the default route
+const defaultRouteProbe = "8.8.8.8"
+
+// pseudoDevices are name prefixes of cards that don't carry network traffic,
+// or only as a copy of other cards, like the Linux any device
+var pseudoDevices = []string{"any", "nflog", "nfqueue", "dbus-", "usbmon", "bluetooth"}
+
+// capturable reports whether "all" includes device: cards that are up, but
+// not loopback or pseudo devices
+func capturable(device pcap.Interface) bool {
+	if device.Flags&pcapIfUp == 0 || device.Flags&pcapIfLoopback != 0 {
+		return false
+	}
+	for _, prefix := range pseudoDevices {
+		if strings.HasPrefix(device.Name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// return card raw names for a comma separated list of interfaces, or all
+// capturable cards for "all"
+func devicesByList(list string) ([]string, error) {
+	devices, err := pcap.FindAllDevs()
+	if err != nil {
+		return nil, err
+	}
+	if list == "all" {
+		names := devicesMatching(devices, capturable)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("No interface found")
+		}
+		return names, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, spec := range strings.Split(list, ",") {
+		found, err := resolveInterface(devices, strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range found {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// resolveInterface returns the cards spec refers to, as given to -interface
+// or sent in collector commands. spec is tried in this order as
+//
+//	a number           the card shown under it by -listNIC
+//	default            the card holding the default route
+//	a name             the card's raw name
+//	an IP address      the card owning it
+//	a CIDR network     every card with an address in it
+//	/regexp/           every card whose name or description matches
+//	anything else      the one card whose description contains it, ignoring case
+func resolveInterface(devices []pcap.Interface, spec string) ([]string, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("Empty interface name")
+	}
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 || n > len(devices) {
+			return nil, fmt.Errorf("Interface %d not found, try -listNIC", n)
+		}
+		return []string{devices[n-1].Name}, nil
+	}
+
+	if spec == "default" {
+		ip := net.ParseIP(localIP(defaultRouteProbe))
+		if ip == nil {
+			return nil, fmt.Errorf("No default route found")
+		}
+		if names := devicesMatching(devices, ownsIP(ip)); len(names) > 0 {
+			return names[:1], nil
+		}
+		return nil, fmt.Errorf("No interface holds the default route address %s", ip)
+	}
+
+	for _, device := range devices {
+		if device.Name == spec {
+			return []string{device.Name}, nil
+		}
+	}
+
+	if ip := net.ParseIP(spec); ip != nil {
+		if names := devicesMatching(devices, ownsIP(ip)); len(names) > 0 {
+			return names, nil
+		}
+		return nil, fmt.Errorf("No interface has the address %s", ip)
+	}
+
+	if _, network, err := net.ParseCIDR(spec); err == nil {
+		names := devicesMatching(devices, func(d pcap.Interface) bool {
+			for _, address := range d.Addresses {
+				if network.Contains(address.IP) {
+					return true
+				}
+			}
+			return false
+		})
+		if len(names) > 0 {
+			return names, nil
+		}
+		return nil, fmt.Errorf("No interface has an address in %s", network)
+	}
+
+	if len(spec) > 2 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/") {
+		re, err := regexp.Compile(spec[1 : len(spec)-1])
+		if err != nil {
+			return nil, fmt.Errorf("Bad interface pattern %s: %v", spec, err)
+		}
+		names := devicesMatching(devices, func(d pcap.Interface) bool {
+			return re.MatchString(d.Name) || re.MatchString(d.Description)
+		})
+		if len(names) > 0 {
+			return names, nil
+		}
+		return nil, fmt.Errorf("No interface matches %s", spec)
+	}
+
+	lower := strings.ToLower(spec)
+	names := devicesMatching(devices, func(d pcap.Interface) bool {
+		return d.Description != "" && strings.Contains(strings.ToLower(d.Description), lower)
+	})
+	switch len(names) {
+	case 0:
+		return nil, fmt.Errorf("Interface %s not found", spec)
+	case 1:
+		return names, nil
+	}
+	return nil, fmt.Errorf("Interface %s is ambiguous, it matches %s", spec, strings.Join(names, ", "))
+}
+
+func devicesMatching(devices []pcap.Interface, match func(pcap.Interface) bool) []string {
+	var names []string
+	for _, device := range devices {
+		if match(device) {
+			names = append(names, device.Name)
+		}
+	}
+	return names
+}
+
+func ownsIP(ip net.IP) func(pcap.Interface) bool {
+	return func(d pcap.Interface) bool {
+		for _, address := range d.Addresses {
+			if address.IP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/gopacket/pcap"
+)
+
+func TestCapturable(t *testing.T) {
+	up := uint32(pcapIfUp | pcapIfRunning)
+	tests := []struct {
+		device pcap.Interface
+		want   bool
+	}{
+		{pcap.Interface{Name: "eth0", Flags: up}, true},
+		{pcap.Interface{Name: "wlan0", Flags: up | pcapIfWireless}, true},
+		{pcap.Interface{Name: "eth1"}, false},
+		{pcap.Interface{Name: "lo", Flags: up | pcapIfLoopback}, false},
+		{pcap.Interface{Name: "any", Flags: up}, false},
+		{pcap.Interface{Name: "nflog", Flags: up}, false},
+		{pcap.Interface{Name: "nfqueue", Flags: up}, false},
+		{pcap.Interface{Name: "dbus-system", Flags: up}, false},
+		{pcap.Interface{Name: "usbmon1", Flags: up}, false},
+		{pcap.Interface{Name: "bluetooth-monitor", Flags: up}, false},
+	}
+	for _, tt := range tests {
+		if got := capturable(tt.device); got != tt.want {
+			t.Errorf("capturable(%s) = %v, want %v", tt.device.Name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"github.com/google/gopacket"
+	"google.golang.org/grpc"
+)
+
+// packetSender delivers packets on one Capture or CaptureBatch stream
+type packetSender interface {
+	// Send queues pkt, batching senders send once the batch is full
+	Send(pkt *service.Packet) error
+	// SendAll sends pkts right away
+	SendAll(pkts []*service.Packet) error
+	// Flush sends a partially filled batch
+	Flush() error
+	// Unsent returns the packets that were queued or failed to send
+	Unsent() []*service.Packet
+	// Close flushes and closes the stream, the summary is nil for servers
+	// that don't send one
+	Close() (*service.CaptureSummary, error)
+}
+
+// openSender opens the stream GetReady negotiated under the session of reply,
+// paced to its max rate
+func openSender(ctx context.Context, client service.RemoteCaputreClient, reply *service.ReadyReply) (packetSender, error) {
+	sender, err := openStream(ctx, client, reply)
+	if err != nil || reply.GetMaxRate() == 0 {
+		return sender, err
+	}
+	return &pacedSender{packetSender: sender, rate: int64(reply.GetMaxRate())}, nil
+}
+
+func openStream(ctx context.Context, client service.RemoteCaputreClient, reply *service.ReadyReply) (packetSender, error) {
+	// streams are matched to this registration by the session id
+	streamCtx := sessionContext(ctx, reply)
+	var opts []grpc.CallOption
+	if name := streamCompressor(reply); name != "" {
+		verbosePrint(fmt.Sprintf("Compressing with %s", name))
+		opts = append(opts, grpc.UseCompressor(name))
+	}
+	if reply.GetBatching() {
+		verbosePrint(fmt.Sprintf("Batching up to %d packets", *batchCount))
+		stream, err := client.CaptureBatch(streamCtx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("open stream error %v", err)
+		}
+		return &batchSender{stream: stream, b: newBatcher(*batchCount, *batchBytes)}, nil
+	}
+	stream, err := client.Capture(streamCtx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("open stream error %v", err)
+	}
+	legacy := reply.GetCaptureInfoFormat() == service.CaptureInfoFormat_LEGACY_JSON
+	return &singleSender{stream: stream, legacy: legacy}, nil
+}
+
+// pacedSender holds packets back so that no more than rate bytes of packet
+// data are sent per second. An idle stream saves up at most a second of
+// sending.
+type pacedSender struct {
+	packetSender
+	rate int64
+	// next is when the data sent so far is due at rate
+	next time.Time
+}
+
+func (p *pacedSender) pace(n int) {
+	now := time.Now()
+	if p.next.Before(now.Add(-time.Second)) {
+		p.next = now.Add(-time.Second)
+	}
+	p.next = p.next.Add(time.Duration(int64(n) * int64(time.Second) / p.rate))
+	if wait := p.next.Sub(now); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (p *pacedSender) Send(pkt *service.Packet) error {
+	p.pace(len(pkt.Data))
+	return p.packetSender.Send(pkt)
+}
+
+func (p *pacedSender) SendAll(pkts []*service.Packet) error {
+	n := 0
+	for _, pkt := range pkts {
+		n += len(pkt.Data)
+	}
+	p.pace(n)
+	return p.packetSender.SendAll(pkts)
+}
+
+// singleSender sends every packet in its own frame on a Capture stream
+type singleSender struct {
+	stream service.RemoteCaputre_CaptureClient
+	legacy bool
+	unsent []*service.Packet
+}
+
+func (s *singleSender) Send(pkt *service.Packet) error {
+	frame := pkt
+	if s.legacy {
+		frame = legacyPacket(pkt)
+	}
+	if err := s.stream.Send(frame); err != nil {
+		s.unsent = []*service.Packet{pkt}
+		return sendError(err, s.stream.CloseAndRecv)
+	}
+	return nil
+}
+
+func (s *singleSender) SendAll(pkts []*service.Packet) error {
+	for i, pkt := range pkts {
+		if err := s.Send(pkt); err != nil {
+			s.unsent = pkts[i:]
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *singleSender) Flush() error {
+	return nil
+}
+
+func (s *singleSender) Unsent() []*service.Packet {
+	return s.unsent
+}
+
+func (s *singleSender) Close() (*service.CaptureSummary, error) {
+	return streamError(s.stream.CloseAndRecv())
+}
+
+// legacyPacket moves the capture info of pkt to the JSON encoded field read
+// by servers predating the typed CaptureInfo
+func legacyPacket(pkt *service.Packet) *service.Packet {
+	ci := pkt.GetInfo().GopacketCaptureInfo()
+	metadata := gopacket.PacketMetadata{CaptureInfo: ci, Truncated: ci.CaptureLength < ci.Length}
+	byteArray, err := json.Marshal(metadata)
+	if err != nil {
+		fmt.Println(err)
+	}
+	return &service.Packet{Data: pkt.Data, Seralizedcapturreinfo: byteArray}
+}
+
+// streamError turns the result of CloseAndRecv into the summary of the stream
+// and the error that ended it. Servers predating the summary report a clean
+// close as io.EOF.
+func streamError(summary *service.CaptureSummary, err error) (*service.CaptureSummary, error) {
+	if err == io.EOF {
+		return nil, nil
+	}
+	return summary, err
+}
+
+// sendError returns why Send failed, the status of a stream the server ended
+// is only available from CloseAndRecv
+func sendError(err error, closeAndRecv func() (*service.CaptureSummary, error)) error {
+	if err != io.EOF {
+		return err
+	}
+	if _, err := streamError(closeAndRecv()); err != nil {
+		return err
+	}
+	return fmt.Errorf("collector closed the stream")
+}
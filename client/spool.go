@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// evictOldest drops the oldest spooled segment to make room for new packets
+	evictOldest = "oldest"
+	// evictNewest drops new packets while the spool is full
+	evictNewest = "newest"
+
+	spoolSuffix = ".spool"
+	// spoolReplayFile holds how far the spool was replayed, spoolInstanceFile
+	// the instance its packets were numbered by
+	spoolReplayFile   = "replay"
+	spoolInstanceFile = "instance"
+)
+
+// spool is a bounded on-disk FIFO of packets captured while the collector is
+// unreachable. Packets are appended as length prefixed records to numbered
+// segment files, segments are deleted once they were replayed or evicted.
+// Segments left over by a previous run are replayed as well, from where that
+// run stopped, and keep the instance they were numbered by so the collector
+// tells packets delivered twice.
+type spool struct {
+	mu          sync.Mutex
+	dir         string
+	maxBytes    int64
+	segmentSize int64
+	evict       string
+	segments    []spoolSegment
+	size        int64
+	readOffset  int64
+	writer      *os.File
+	buf         *bufio.Writer
+	dropped     uint64
+	instance    string
+}
+
+type spoolSegment struct {
+	seq  uint64
+	size int64
+}
+
+// openSpool opens or creates the spool in dir holding up to maxBytes
+func openSpool(dir string, maxBytes int64, evict string) (*spool, error) {
+	if evict != evictOldest && evict != evictNewest {
+		return nil, fmt.Errorf("unknown spool eviction policy %q", evict)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &spool{
+		dir:         dir,
+		maxBytes:    maxBytes,
+		segmentSize: maxBytes / 16,
+		evict:       evict,
+	}
+	if s.segmentSize < 1<<20 {
+		s.segmentSize = 1 << 20
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), spoolSuffix), 10, 64)
+		if err != nil || !strings.HasSuffix(f.Name(), spoolSuffix) {
+			continue
+		}
+		s.segments = append(s.segments, spoolSegment{seq: seq, size: f.Size()})
+		s.size += f.Size()
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	if s.size > 0 {
+		fmt.Printf("Spool holds %d bytes from a previous run\n", s.size)
+	}
+	s.loadReplay()
+	if err := s.loadInstance(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadReplay resumes reading where a previous run stopped, a missing or
+// stale replay file starts at the first segment
+func (s *spool) loadReplay() {
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, spoolReplayFile))
+	if err != nil || len(s.segments) == 0 {
+		return
+	}
+	var seq uint64
+	var offset int64
+	if _, err := fmt.Sscanf(string(b), "%d %d", &seq, &offset); err != nil {
+		return
+	}
+	if first := s.segments[0]; first.seq == seq && offset >= 0 && offset <= first.size {
+		s.readOffset = offset
+	}
+}
+
+// loadInstance keeps the instance of a previous run while its packets are
+// spooled, numbering starts over with a new one otherwise
+func (s *spool) loadInstance() error {
+	path := filepath.Join(s.dir, spoolInstanceFile)
+	if b, err := ioutil.ReadFile(path); err == nil && s.size > 0 {
+		if s.instance = strings.TrimSpace(string(b)); s.instance != "" {
+			return nil
+		}
+	}
+	s.instance = newInstance()
+	return ioutil.WriteFile(path, []byte(s.instance+"\n"), 0600)
+}
+
+// saveReplay records how far the spool was replayed, the file is replaced
+// whole so that a crash leaves the previous one
+func (s *spool) saveReplay() error {
+	if len(s.segments) == 0 {
+		return nil
+	}
+	tmp := filepath.Join(s.dir, spoolReplayFile+".tmp")
+	b := []byte(fmt.Sprintf("%d %d\n", s.segments[0].seq, s.readOffset))
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, spoolReplayFile))
+}
+
+func (s *spool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSuffix))
+}
+
+// Write appends pkts, dropping packets or old segments as the eviction policy says when full
+func (s *spool) Write(pkts ...*service.Packet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pkt := range pkts {
+		data, err := proto.Marshal(pkt)
+		if err != nil {
+			return err
+		}
+		record := int64(4 + len(data))
+		for s.size+record > s.maxBytes {
+			if s.evict == evictNewest || !s.evictOldest() {
+				break
+			}
+		}
+		if s.size+record > s.maxBytes {
+			s.dropped++
+			continue
+		}
+		if err := s.append(data); err != nil {
+			return err
+		}
+	}
+	// a spool is only useful when it survives the agent being killed
+	if s.buf != nil {
+		return s.buf.Flush()
+	}
+	return nil
+}
+
+// append writes one record to the newest segment, starting a new one when it is full
+func (s *spool) append(data []byte) error {
+	last := len(s.segments) - 1
+	if s.writer == nil || s.segments[last].size >= s.segmentSize {
+		if err := s.closeWriter(); err != nil {
+			return err
+		}
+		var seq uint64 = 1
+		if last >= 0 {
+			seq = s.segments[last].seq + 1
+		}
+		f, err := os.OpenFile(s.path(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		s.writer, s.buf = f, bufio.NewWriter(f)
+		s.segments = append(s.segments, spoolSegment{seq: seq})
+		last = len(s.segments) - 1
+	}
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	s.buf.Write(length[:])
+	if _, err := s.buf.Write(data); err != nil {
+		return err
+	}
+	s.segments[last].size += int64(4 + len(data))
+	s.size += int64(4 + len(data))
+	return nil
+}
+
+func (s *spool) closeWriter() error {
+	if s.writer == nil {
+		return nil
+	}
+	err := s.buf.Flush()
+	if cerr := s.writer.Close(); err == nil {
+		err = cerr
+	}
+	s.writer, s.buf = nil, nil
+	return err
+}
+
+// evictOldest removes the oldest segment, the one being written is never evicted
+func (s *spool) evictOldest() bool {
+	if len(s.segments) < 2 {
+		return false
+	}
+	s.removeFirst()
+	return true
+}
+
+func (s *spool) removeFirst() {
+	first := s.segments[0]
+	os.Remove(s.path(first.seq))
+	s.segments = s.segments[1:]
+	s.size -= first.size
+	s.readOffset = 0
+}
+
+// spoolMark is how far a Peek read into the spool
+type spoolMark struct {
+	seq    uint64
+	offset int64
+}
+
+// Peek returns up to maxCount packets from the front of the spool without
+// removing them, along with the mark to pass to Discard once they were
+// delivered. It returns no packets when the spool is empty.
+func (s *spool) Peek(maxCount int, maxBytes int) ([]*service.Packet, spoolMark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.segments) > 0 {
+		first := s.segments[0]
+		if s.readOffset >= first.size {
+			if len(s.segments) == 1 {
+				// fully replayed, start over with a fresh segment
+				s.closeWriter()
+			}
+			s.removeFirst()
+			continue
+		}
+		pkts, read, err := s.read(first, s.readOffset, maxCount, maxBytes)
+		if err != nil {
+			return nil, spoolMark{}, err
+		}
+		if len(pkts) > 0 {
+			return pkts, spoolMark{seq: first.seq, offset: s.readOffset + read}, nil
+		}
+		// a record cut short by a crash or corrupt, skip the rest of the segment
+		s.readOffset = first.size
+	}
+	return nil, spoolMark{}, nil
+}
+
+// read decodes packets of segment starting at offset
+func (s *spool) read(segment spoolSegment, offset int64, maxCount int, maxBytes int) ([]*service.Packet, int64, error) {
+	f, err := os.Open(s.path(segment.seq))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(io.NewSectionReader(f, offset, segment.size-offset))
+
+	var pkts []*service.Packet
+	var read int64
+	size := 0
+	for len(pkts) < maxCount && size < maxBytes {
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			break
+		}
+		// a length beyond the segment is a corrupt record, not one to allocate
+		n := int64(binary.BigEndian.Uint32(length[:]))
+		if n > segment.size-offset-read-4 {
+			break
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			break
+		}
+		pkt := &service.Packet{}
+		if err := proto.Unmarshal(data, pkt); err != nil {
+			break
+		}
+		pkts = append(pkts, pkt)
+		read += int64(4 + len(data))
+		size += len(data)
+	}
+	return pkts, read, nil
+}
+
+// Discard removes the packets up to mark from the front of the spool, unless
+// they were evicted in the meantime
+func (s *spool) Discard(mark spoolMark) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.segments) > 0 && s.segments[0].seq == mark.seq && mark.offset > s.readOffset {
+		s.readOffset = mark.offset
+		if err := s.saveReplay(); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// LastSequence returns the highest sequence number in the spool, so that a
+// restarted client keeps numbering after the packets a previous run spooled
+func (s *spool) LastSequence() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.segments) - 1; i >= 0; i-- {
+		pkts, _, err := s.read(s.segments[i], 0, math.MaxInt32, math.MaxInt32)
+		if err != nil {
+			return 0, err
+		}
+		if len(pkts) > 0 {
+			return pkts[len(pkts)-1].Sequence, nil
+		}
+	}
+	return 0, nil
+}
+
+// Instance returns the instance the spooled packets are numbered by
+func (s *spool) Instance() string {
+	return s.instance
+}
+
+// Dropped returns how many packets were lost because the spool was full
+func (s *spool) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+)
+
+// spoolPacket is a packet numbered seq with size bytes of data
+func spoolPacket(seq uint64, size int) *service.Packet {
+	return &service.Packet{Data: make([]byte, size), Sequence: seq, Info: &service.CaptureInfo{Length: int64(size)}}
+}
+
+// drain reads the spool empty and returns the sequence numbers in the order read
+func drain(t *testing.T, s *spool) []uint64 {
+	t.Helper()
+	var seqs []uint64
+	for {
+		pkts, mark, err := s.Peek(64, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pkts) == 0 {
+			return seqs
+		}
+		for _, pkt := range pkts {
+			seqs = append(seqs, pkt.Sequence)
+		}
+		s.Discard(mark)
+	}
+}
+
+func TestSpoolEviction(t *testing.T) {
+	const written = 5000
+	tests := []struct {
+		evict string
+		// whether the first and last packets written survive
+		first, last bool
+		dropped     bool
+	}{
+		{evict: evictOldest, first: false, last: true, dropped: false},
+		{evict: evictNewest, first: true, last: false, dropped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.evict, func(t *testing.T) {
+			s, err := openSpool(t.TempDir(), 3<<20, tt.evict)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for seq := uint64(1); seq <= written; seq++ {
+				if err := s.Write(spoolPacket(seq, 1000)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if s.size > s.maxBytes {
+				t.Errorf("spool holds %d bytes, more than %d", s.size, s.maxBytes)
+			}
+			if (s.Dropped() > 0) != tt.dropped {
+				t.Errorf("dropped %d packets", s.Dropped())
+			}
+			seqs := drain(t, s)
+			if len(seqs) == 0 || len(seqs) == written {
+				t.Fatalf("read %d packets of %d written to a full spool", len(seqs), written)
+			}
+			for i := 1; i < len(seqs); i++ {
+				if seqs[i] != seqs[i-1]+1 {
+					t.Fatalf("packet %d follows %d", seqs[i], seqs[i-1])
+				}
+			}
+			if (seqs[0] == 1) != tt.first || (seqs[len(seqs)-1] == written) != tt.last {
+				t.Errorf("read packets %d to %d", seqs[0], seqs[len(seqs)-1])
+			}
+		})
+	}
+}
+
+func TestSpoolReplaysPreviousRun(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 10; seq++ {
+		if err := s.Write(spoolPacket(seq, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first three were delivered before the agent stopped
+	pkts, mark, err := s.Peek(3, 1<<20)
+	if err != nil || len(pkts) != 3 {
+		t.Fatalf("peeked %d packets: %v", len(pkts), err)
+	}
+	s.Discard(mark)
+	s.closeWriter()
+
+	s, err = openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.LastSequence(); err != nil || last != 10 {
+		t.Errorf("last sequence %d, %v, want 10", last, err)
+	}
+	// a previous run is replayed from where it stopped
+	if seqs := drain(t, s); len(seqs) != 7 || seqs[0] != 4 {
+		t.Errorf("replayed %v", seqs)
+	}
+	if seqs := drain(t, s); len(seqs) != 0 {
+		t.Errorf("replayed %v twice", seqs)
+	}
+}
+
+func TestSpoolInstance(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Instance()
+	if err := s.Write(spoolPacket(1, 100)); err != nil {
+		t.Fatal(err)
+	}
+	s.closeWriter()
+
+	// spooled packets keep the instance they were numbered by
+	s, err = openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Instance() != first {
+		t.Errorf("instance %q after a restart, want %q", s.Instance(), first)
+	}
+	drain(t, s)
+	s.closeWriter()
+
+	// numbering starts over once the spool is empty
+	s, err = openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Instance() == first || s.Instance() == "" {
+		t.Errorf("instance %q of an empty spool, want a new one", s.Instance())
+	}
+}
+
+func TestSpoolSkipsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 3; seq++ {
+		s.Write(spoolPacket(seq, 100))
+	}
+	s.closeWriter()
+	// a crash cut the last record short
+	path := s.path(s.segments[0].seq)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, fi.Size()-10); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seqs := drain(t, s); len(seqs) != 2 || seqs[1] != 2 {
+		t.Errorf("read %v from a torn spool, want 1 and 2", seqs)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*"+spoolSuffix)); len(files) != 0 {
+		t.Errorf("replayed segments left: %v", files)
+	}
+}
+
+func TestSpoolSkipsOversizedRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 2; seq++ {
+		s.Write(spoolPacket(seq, 100))
+	}
+	s.closeWriter()
+	// a corrupt length prefix after the first record claims 4 GiB
+	f, err := os.OpenFile(s.path(s.segments[0].seq), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, s.segments[0].size/2)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = openSpool(dir, 3<<20, evictOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seqs := drain(t, s); len(seqs) != 1 || seqs[0] != 1 {
+		t.Errorf("read %v from a corrupt spool, want 1", seqs)
+	}
+}
+
+func TestOpenSpoolEvictionPolicy(t *testing.T) {
+	if _, err := openSpool(t.TempDir(), 1<<20, "random"); err == nil {
+		t.Error("opened a spool with an unknown eviction policy")
+	}
+}
@@ -5,10 +5,13 @@ go 1.15
 require (
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d // indirect
 	github.com/golang/protobuf v1.5.2
+	github.com/golang/snappy v0.0.4
 	github.com/google/gopacket v1.1.19
 	github.com/hashicorp/golang-lru v0.5.4 // indirect
 	github.com/jbenet/go-is-domain v1.0.5 // indirect
+	github.com/klauspost/compress v1.15.0
 	golang.org/x/net v0.0.0-20210716203947-853a461950ff
 	google.golang.org/grpc v1.39.0
 	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v3 v3.0.1
 )
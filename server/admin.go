@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+)
+
+// commandTimeout bounds how long the admin API waits for an agent to acknowledge a command
+const commandTimeout = 30 * time.Second
+
+// adminHandler serves the operator API, it must only be reachable from trusted hosts
+//
+//	GET  /endpoints                                   registered endpoints as JSON
+//	GET  /stats                                       totals over all endpoints
+//	POST /control?session=ID&command=start&interface=2&filter=tcp
+//	GET  /tokens                                      enrollment tokens, with -auth
+//	POST /tokens?uses=1&ttl=24h&comment=branch        new token, its secret is only shown here
+//	POST /tokens?hostname=web01                       token for one hostname, also enrolls it after a revocation
+//	DELETE /tokens?id=ID                              revoke a token
+//	GET  /agents                                      enrolled agents
+//	DELETE /agents?id=ID                              revoke the credential of an agent
+//	GET  /agents/revoked                              revoked agents, by hostname
+//	GET  /traces                                      complete traces under the storage dirs
+//	GET  /traces/download?path=PATH                   a trace, decompressed unless raw=1
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/endpoints", s.listEndpoints)
+	mux.HandleFunc("/stats", s.serverStats)
+	mux.HandleFunc("/control", s.sendCommand)
+	mux.HandleFunc("/tokens", s.manageTokens)
+	mux.HandleFunc("/agents", s.manageAgents)
+	mux.HandleFunc("/agents/revoked", s.revokedAgents)
+	mux.HandleFunc("/traces", s.listTraces)
+	mux.HandleFunc("/traces/download", s.downloadTrace)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func (s *Server) listEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, s.endpoints.Endpoints())
+}
+
+// stats sums up what the collector received from all known endpoints
+type stats struct {
+	Endpoints  int
+	Streaming  int
+	Packets    int
+	Gaps       uint64
+	Missing    uint64
+	Late       uint64
+	Duplicates uint64
+	Unmatched  uint64
+	// packets dropped on the endpoints by the kernel and by their interfaces
+	Dropped   int64
+	IfDropped int64
+}
+
+func (s *Server) serverStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var st stats
+	for _, e := range s.endpoints.Endpoints() {
+		st.Endpoints++
+		if e.State == stateStreaming || e.State == stateIdle {
+			st.Streaming++
+		}
+		st.Packets += e.Packetcount
+		st.Gaps += e.Sequence.Gaps
+		st.Missing += e.Sequence.Missing
+		st.Late += e.Sequence.Late
+		st.Duplicates += e.Sequence.Duplicates
+		st.Unmatched += e.Sequence.Unmatched
+		for _, c := range e.Captures {
+			st.Dropped += c.Dropped
+			st.IfDropped += c.IfDropped
+		}
+	}
+	writeJSON(w, st)
+}
+
+func (s *Server) sendCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session := r.FormValue("session")
+	cmdType, ok := service.CommandType_value[strings.ToUpper(r.FormValue("command"))]
+	if !ok || cmdType == int32(service.CommandType_NOOP) {
+		http.Error(w, "command must be one of start, stop, pause, resume, set_filter", http.StatusBadRequest)
+		return
+	}
+	if !s.control.Connected(session) {
+		http.Error(w, fmt.Sprintf("session %q has no control stream", session), http.StatusNotFound)
+		return
+	}
+
+	cmd := &service.Command{
+		Type:      service.CommandType(cmdType),
+		Interface: r.FormValue("interface"),
+		Filter:    r.FormValue("filter"),
+	}
+	reply, err := s.control.Send(session, cmd, commandTimeout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		return
+	}
+	writeJSON(w, reply)
+}
+
+// newToken is what POST /tokens answers, Token is what agents enroll with
+type newToken struct {
+	enrollToken
+	Token string
+}
+
+func (s *Server) manageTokens(w http.ResponseWriter, r *http.Request) {
+	if s.enroll == nil {
+		http.Error(w, "enrollment is disabled, start the server with -auth", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.enroll.Tokens())
+	case http.MethodPost:
+		uses := 1
+		if v := r.FormValue("uses"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "uses must be a number, 0 for any number of agents", http.StatusBadRequest)
+				return
+			}
+			uses = n
+		}
+		var ttl time.Duration
+		if v := r.FormValue("ttl"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				http.Error(w, "ttl must be a duration such as 24h", http.StatusBadRequest)
+				return
+			}
+			ttl = d
+		}
+		t, token, err := s.enroll.CreateToken(uses, ttl, r.FormValue("hostname"), r.FormValue("comment"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, newToken{t, token})
+	case http.MethodDelete:
+		revoke(w, r, s.enroll.RevokeToken)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) manageAgents(w http.ResponseWriter, r *http.Request) {
+	if s.enroll == nil {
+		http.Error(w, "enrollment is disabled, start the server with -auth", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.enroll.Agents())
+	case http.MethodDelete:
+		revoke(w, r, s.enroll.RevokeAgent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) revokedAgents(w http.ResponseWriter, r *http.Request) {
+	if s.enroll == nil {
+		http.Error(w, "enrollment is disabled, start the server with -auth", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, s.enroll.Revoked())
+}
+
+// revoke answers DELETE requests for the token or agent given as id
+func revoke(w http.ResponseWriter, r *http.Request, revokeID func(string) (bool, error)) {
+	id := r.FormValue("id")
+	found, err := revokeID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("%q not found", id), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// storedTrace is a complete trace as listed by GET /traces
+type storedTrace struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+	// Compression is empty for uncompressed traces
+	Compression string `json:",omitempty"`
+}
+
+func (s *Server) listTraces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list := []storedTrace{}
+	seen := make(map[string]bool)
+	for _, root := range s.config().traceRoots() {
+		filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() || !isTrace(fi.Name()) || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			compression, _, _ := codecOf(path)
+			list = append(list, storedTrace{Path: path, Size: fi.Size(), Modified: fi.ModTime(), Compression: compression})
+			return nil
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	writeJSON(w, list)
+}
+
+// downloadTrace sends a complete trace under one of the storage dirs,
+// compressed traces are sent decompressed unless raw is set
+func (s *Server) downloadTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := filepath.Clean(r.FormValue("path"))
+	if !isTrace(path) || !s.config().underTraceRoot(path) {
+		http.Error(w, fmt.Sprintf("%q is not a trace", r.FormValue("path")), http.StatusNotFound)
+		return
+	}
+
+	name := filepath.Base(path)
+	var trace io.ReadCloser
+	var err error
+	if r.FormValue("raw") != "" {
+		trace, err = os.Open(path)
+	} else {
+		trace, err = openTraceFile(path)
+		name = uncompressedName(name)
+	}
+	if os.IsNotExist(err) {
+		http.Error(w, fmt.Sprintf("%q not found", r.FormValue("path")), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer trace.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	if _, err := io.Copy(w, trace); err != nil {
+		fmt.Printf("download of %s: %v\n", path, err)
+	}
+}
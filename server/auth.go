@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticate returns the session of ctx. Sessions registered with a client
+// certificate can only be used with that same certificate, and when agents
+// must enroll only with the credential of the agent that registered them.
+func (s *Server) authenticate(ctx context.Context) (string, error) {
+	session, err := sessionID(ctx)
+	if err != nil {
+		return "", err
+	}
+	var agent string
+	if s.enroll != nil {
+		if agent, err = s.verifyAgent(ctx); err != nil {
+			return "", err
+		}
+	}
+	e, ok := s.endpoints.Lookup(session)
+	if !ok {
+		return session, nil
+	}
+	if subject, _ := peerIdentity(ctx); e.Identity != subject {
+		return "", status.Errorf(codes.PermissionDenied, "session %s belongs to another client certificate", session)
+	}
+	if e.Agent != agent {
+		return "", status.Errorf(codes.PermissionDenied, "session %s belongs to another agent", session)
+	}
+	return session, nil
+}
+
+// verifyAgent returns the enrolled agent whose credential ctx carries
+func (s *Server) verifyAgent(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	credentials := md.Get(service.CredentialMetadataKey)
+	if len(credentials) == 0 || credentials[0] == "" {
+		return "", status.Error(codes.Unauthenticated, "missing agent credential, enroll with a token first")
+	}
+	agent, ok := s.enroll.Verify(credentials[0])
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "invalid or revoked agent credential")
+	}
+	return agent, nil
+}
+
+// enrollAgent returns the agent registering in GetReady. Agents without a
+// valid credential enroll with the token in info, their new credential is
+// returned as well.
+func (s *Server) enrollAgent(ctx context.Context, info *service.EndpointInfo, hostname string) (agent string, credential string, err error) {
+	agent, err = s.verifyAgent(ctx)
+	if err == nil || info.EnrollmentToken == "" {
+		return agent, "", err
+	}
+	agent, credential, err = s.enroll.Enroll(info.EnrollmentToken, hostname)
+	if err != nil {
+		return "", "", status.Errorf(codes.Unauthenticated, "can not enroll: %v", err)
+	}
+	fmt.Printf("%s enrolled as agent %s\n", hostname, agent)
+	return agent, credential, nil
+}
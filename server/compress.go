@@ -0,0 +1,121 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressNone = "none"
+	compressGzip = "gzip"
+	compressZstd = "zstd"
+)
+
+// compressor compresses a trace as it is written, Flush pushes out what was
+// written so far
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// traceCodec is a compression traces can be stored with, known by the extension it adds
+type traceCodec struct {
+	ext       string
+	newWriter func(io.Writer) compressor
+	newReader func(io.Reader) (io.ReadCloser, error)
+}
+
+var traceCodecs = map[string]traceCodec{
+	compressGzip: {
+		ext:       ".gz",
+		newWriter: func(w io.Writer) compressor { return gzip.NewWriter(w) },
+		newReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	},
+	compressZstd: {
+		ext:       ".zst",
+		newWriter: newZstdWriter,
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		},
+	},
+}
+
+// newZstdWriter compresses a trace with zstd, on one goroutine as every
+// stream writes a trace of its own
+func newZstdWriter(w io.Writer) compressor {
+	// only invalid options fail
+	e, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	return e
+}
+
+func validCompression(name string) error {
+	switch name {
+	case "", compressNone:
+		return nil
+	}
+	if _, ok := traceCodecs[name]; !ok {
+		return fmt.Errorf("unknown compression %q", name)
+	}
+	return nil
+}
+
+// codecOf returns the compression of a trace and its codec by the name of the
+// trace, false for uncompressed traces
+func codecOf(path string) (string, traceCodec, bool) {
+	for name, codec := range traceCodecs {
+		if strings.HasSuffix(path, codec.ext) {
+			return name, codec, true
+		}
+	}
+	return "", traceCodec{}, false
+}
+
+// openTraceFile opens a complete trace for reading, compressed traces are
+// decompressed on the fly
+func openTraceFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	_, codec, ok := codecOf(path)
+	if !ok {
+		return f, nil
+	}
+	r, err := codec.newReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &decompressedFile{ReadCloser: r, file: f}, nil
+}
+
+// decompressedFile closes the file along with its decompressor
+type decompressedFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (d *decompressedFile) Close() error {
+	err := d.ReadCloser.Close()
+	if ferr := d.file.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// uncompressedName returns the name of a trace without the extension of its compression
+func uncompressedName(path string) string {
+	if _, codec, ok := codecOf(path); ok {
+		return strings.TrimSuffix(path, codec.ext)
+	}
+	return path
+}
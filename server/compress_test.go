@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcapgo"
+)
+
+func TestValidCompression(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"", true},
+		{compressNone, true},
+		{compressGzip, true},
+		{compressZstd, true},
+		{"lz4", false},
+	}
+	for _, tt := range tests {
+		if err := validCompression(tt.name); (err == nil) != tt.valid {
+			t.Errorf("validCompression(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestUncompressedName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a/trace.pcap", "a/trace.pcap"},
+		{"a/trace.pcapng.gz", "a/trace.pcapng"},
+		{"a/trace.pcap.zst", "a/trace.pcap"},
+		{"a/trace.pcap.zst.gaps", "a/trace.pcap.zst.gaps"},
+	}
+	for _, tt := range tests {
+		if got := uncompressedName(tt.path); got != tt.want {
+			t.Errorf("uncompressedName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompressedTraceRoundTrip(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, compression := range []string{compressGzip, compressZstd} {
+		for _, format := range []string{formatPcap, formatPcapng} {
+			t.Run(compression+"/"+format, func(t *testing.T) {
+				storage := storageConfig{Dir: t.TempDir(), Layout: "{root}/{session}", Format: format, Snaplen: 65535, Compression: compression}
+				s, err := openSegments(storage, endpoint{SessionID: "s"}, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data := make([]byte, 1000)
+				for i := range data {
+					data[i] = byte(i)
+				}
+				for i := 0; i < 5; i++ {
+					s.Ready(now)
+					ci := gopacket.CaptureInfo{Timestamp: now, CaptureLength: len(data), Length: len(data)}
+					if err := s.WritePacket(ci, data); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := s.Close(); err != nil {
+					t.Fatal(err)
+				}
+				_, _, closed := s.Stored()
+				if len(closed) != 1 {
+					t.Fatalf("closed %v, want one trace", closed)
+				}
+				want := filepath.Join(storage.Dir, "s."+format+traceCodecs[compression].ext)
+				if closed[0] != want {
+					t.Fatalf("trace %s, want %s", closed[0], want)
+				}
+				f, err := openTraceFile(closed[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				if n := countPackets(t, f, format); n != 5 {
+					t.Errorf("read %d packets, want 5", n)
+				}
+			})
+		}
+	}
+}
+
+// countPackets reads the packets of a trace in format
+func countPackets(t *testing.T, r io.Reader, format string) int {
+	t.Helper()
+	type packetReader interface {
+		ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	}
+	var pr packetReader
+	var err error
+	if format == formatPcapng {
+		pr, err = pcapgo.NewNgReader(r, pcapgo.DefaultNgReaderOptions)
+	} else {
+		pr, err = pcapgo.NewReader(r)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for {
+		if _, _, err := pr.ReadPacketData(); err == io.EOF {
+			return n
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config is what the server runs with, read from the -config file with the
+// flags given on the command line taking precedence. Everything but the
+// listeners, TLS and the enrollment file is reloaded on SIGHUP.
+type config struct {
+	Listen listenConfig `yaml:"listen"`
+	// Public is the directory served on HTTP, it holds exceptions.list
+	Public   string         `yaml:"public"`
+	TLS      tlsConfig      `yaml:"tls"`
+	Auth     authConfig     `yaml:"auth"`
+	Storage  storageConfig  `yaml:"storage"`
+	Sessions sessionsConfig `yaml:"sessions"`
+	// Retention applies to the traces under the storage dir, except those
+	// under the dir of an endpoint policy with retention of its own
+	Retention retentionConfig `yaml:"retention"`
+	// Compressors are advertised in GetReady for agents to compress their
+	// Capture streams with, preferred first, an empty list asks for none
+	Compressors []string `yaml:"compressors"`
+	// MaxRate is the packet bytes per second agents may send, 0 for no limit
+	MaxRate int64 `yaml:"max_rate"`
+	// Filters are BPF filters agents must capture with, on top of their own
+	Filters []string `yaml:"filters"`
+	// Endpoints are tried in order, the first one matching an endpoint applies to it
+	Endpoints []endpointPolicy `yaml:"endpoints"`
+}
+
+type listenConfig struct {
+	GRPC  string `yaml:"grpc"`
+	HTTP  string `yaml:"http"`
+	Admin string `yaml:"admin"`
+}
+
+type tlsConfig struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	CA   string `yaml:"ca"`
+}
+
+type authConfig struct {
+	File string `yaml:"file"`
+}
+
+// storageConfig says where and how traces are written
+type storageConfig struct {
+	Dir string `yaml:"dir"`
+	// Layout is the path of a trace without extension, {root} stands for Dir
+	Layout string `yaml:"layout"`
+	Format string `yaml:"format"`
+	// Snaplen is the most bytes of a packet stored, longer packets are
+	// truncated. It is written to the traces of clients that don't describe
+	// their capture.
+	Snaplen uint32       `yaml:"snaplen"`
+	Rotate  rotateConfig `yaml:"rotate"`
+	// MinFreeMB is the free space below which new streams are rejected
+	MinFreeMB int64 `yaml:"min_free_mb"`
+	// Compression of the traces, none, gzip or zstd
+	Compression string `yaml:"compression"`
+}
+
+// rotateConfig says when the trace of a stream is closed and the next segment
+// started, whichever limit is reached first. Every aligns rotation to the
+// wall clock, 1h rotates on the hour. Zero values disable a limit.
+type rotateConfig struct {
+	SizeMB   int64         `yaml:"size_mb"`
+	Packets  int           `yaml:"packets"`
+	Duration time.Duration `yaml:"duration"`
+	Every    time.Duration `yaml:"every"`
+}
+
+// enabled reports whether streams are split into segments at all
+func (r rotateConfig) enabled() bool {
+	return r.SizeMB > 0 || r.Packets > 0 || r.Duration > 0 || r.Every > 0
+}
+
+type sessionsConfig struct {
+	IdleAfter   time.Duration `yaml:"idle_after"`
+	ExpireAfter time.Duration `yaml:"expire_after"`
+}
+
+// endpointPolicy applies to endpoints whose hostname matches the Hostname
+// shell pattern and whose address is in Network, an empty one matches any
+type endpointPolicy struct {
+	Hostname string `yaml:"hostname"`
+	Network  string `yaml:"network"`
+	// Deny rejects the endpoint in GetReady
+	Deny bool `yaml:"deny"`
+	// Format, Dir, Layout and Compression replace those of storage
+	Format      string `yaml:"format"`
+	Dir         string `yaml:"dir"`
+	Layout      string `yaml:"layout"`
+	Compression string `yaml:"compression"`
+	// Retention applies to the traces under Dir
+	Retention *retentionConfig `yaml:"retention"`
+	// Compressors replace the advertised compressors when set
+	Compressors []string `yaml:"compressors"`
+	// MaxRate replaces the global limit when set, Filters are required on top of the global ones
+	MaxRate int64    `yaml:"max_rate"`
+	Filters []string `yaml:"filters"`
+
+	network *net.IPNet
+}
+
+var configFile = flag.String("config", "", "YAML configuration file, reloaded on SIGHUP, flags given as well take precedence")
+
+// defaultConfig returns the configuration of a server started without -config
+func defaultConfig() *config {
+	return &config{
+		Listen: listenConfig{
+			GRPC:  *grpcAddr,
+			HTTP:  *httpAddr,
+			Admin: *adminAddr,
+		},
+		Public: "./public",
+		TLS:    tlsConfig{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA},
+		Auth:   authConfig{File: *authFile},
+		Storage: storageConfig{
+			Dir:     ".",
+			Layout:  defaultLayout,
+			Format:  *traceFormat,
+			Snaplen: snapshotLen,
+		},
+		Sessions:    sessionsConfig{IdleAfter: idleAfter, ExpireAfter: expireAfter},
+		Retention:   retentionConfig{Interval: time.Minute},
+		Compressors: service.Compressors(),
+	}
+}
+
+// loadConfig reads the configuration file at path, if any, over the defaults
+// and applies the flags set on the command line on top
+func loadConfig(path string) (*config, error) {
+	c := defaultConfig()
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "listen":
+			c.Listen.GRPC = *grpcAddr
+		case "http":
+			c.Listen.HTTP = *httpAddr
+		case "admin":
+			c.Listen.Admin = *adminAddr
+		case "tlscert":
+			c.TLS.Cert = *tlsCert
+		case "tlskey":
+			c.TLS.Key = *tlsKey
+		case "tlsca":
+			c.TLS.CA = *tlsCA
+		case "auth":
+			c.Auth.File = *authFile
+		case "format":
+			c.Storage.Format = *traceFormat
+		}
+	})
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *config) validate() error {
+	if err := validFormat(c.Storage.Format); err != nil {
+		return err
+	}
+	if err := validLayout(c.Storage.Layout); err != nil {
+		return err
+	}
+	if c.Storage.Snaplen == 0 {
+		return fmt.Errorf("storage snaplen must be positive")
+	}
+	if r := c.Storage.Rotate; r.SizeMB < 0 || r.Packets < 0 || r.Duration < 0 || r.Every < 0 {
+		return fmt.Errorf("rotation limits can't be negative")
+	}
+	if err := validCompression(c.Storage.Compression); err != nil {
+		return err
+	}
+	if c.Storage.MinFreeMB < 0 {
+		return fmt.Errorf("storage min_free_mb can't be negative")
+	}
+	if err := c.Retention.validate(); err != nil {
+		return err
+	}
+	if c.Retention.Interval <= 0 {
+		return fmt.Errorf("retention interval must be positive")
+	}
+	if c.Sessions.IdleAfter <= 0 || c.Sessions.ExpireAfter <= 0 {
+		return fmt.Errorf("session timeouts must be positive")
+	}
+	if err := validCompressors(c.Compressors); err != nil {
+		return err
+	}
+	if c.MaxRate < 0 {
+		return fmt.Errorf("max_rate can't be negative")
+	}
+	for i := range c.Endpoints {
+		p := &c.Endpoints[i]
+		if _, err := path.Match(p.Hostname, ""); err != nil {
+			return fmt.Errorf("endpoint policy %d: bad hostname pattern %q", i+1, p.Hostname)
+		}
+		if p.Network != "" {
+			_, network, err := net.ParseCIDR(p.Network)
+			if err != nil {
+				return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+			}
+			p.network = network
+		}
+		if p.Format != "" {
+			if err := validFormat(p.Format); err != nil {
+				return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+			}
+		}
+		if p.Layout != "" {
+			if err := validLayout(p.Layout); err != nil {
+				return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+			}
+		}
+		if err := validCompression(p.Compression); err != nil {
+			return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+		}
+		if err := validCompressors(p.Compressors); err != nil {
+			return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+		}
+		if p.MaxRate < 0 {
+			return fmt.Errorf("endpoint policy %d: max_rate can't be negative", i+1)
+		}
+		if p.Retention != nil {
+			if p.Dir == "" {
+				return fmt.Errorf("endpoint policy %d: retention needs a dir of its own", i+1)
+			}
+			if err := p.Retention.validate(); err != nil {
+				return fmt.Errorf("endpoint policy %d: %v", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validFormat(format string) error {
+	if format != formatPcap && format != formatPcapng {
+		return fmt.Errorf("unknown trace format %q", format)
+	}
+	return nil
+}
+
+func validCompressors(names []string) error {
+	for _, name := range names {
+		if err := service.CheckCompressor(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// policy returns the first policy matching hostname and address, the zero
+// policy when none does
+func (c *config) policy(hostname string, address string) endpointPolicy {
+	ip := net.ParseIP(address)
+	for _, p := range c.Endpoints {
+		if p.Hostname != "" {
+			if ok, _ := path.Match(p.Hostname, hostname); !ok {
+				continue
+			}
+		}
+		if p.network != nil && (ip == nil || !p.network.Contains(ip)) {
+			continue
+		}
+		return p
+	}
+	return endpointPolicy{}
+}
+
+// storageFor returns the storage settings for traces of e
+func (c *config) storageFor(e endpoint) storageConfig {
+	storage := c.Storage
+	p := c.policy(e.Hostname, e.address())
+	if p.Format != "" {
+		storage.Format = p.Format
+	}
+	if p.Dir != "" {
+		storage.Dir = p.Dir
+	}
+	if p.Layout != "" {
+		storage.Layout = p.Layout
+	}
+	if p.Compression != "" {
+		storage.Compression = p.Compression
+	}
+	return storage
+}
+
+// compressorsFor returns the compressors advertised to e
+func (c *config) compressorsFor(e endpoint) []string {
+	if p := c.policy(e.Hostname, e.address()); p.Compressors != nil {
+		return p.Compressors
+	}
+	return c.Compressors
+}
+
+// limitsFor returns the max rate of e and the filters it must capture with
+func (c *config) limitsFor(e endpoint) (int64, []string) {
+	p := c.policy(e.Hostname, e.address())
+	rate := c.MaxRate
+	if p.MaxRate > 0 {
+		rate = p.MaxRate
+	}
+	filters := append(append([]string{}, c.Filters...), p.Filters...)
+	return rate, filters
+}
+
+// traceRoots returns the storage dir and the dirs of endpoint policies
+func (c *config) traceRoots() []string {
+	roots := []string{c.Storage.Dir}
+	for _, p := range c.Endpoints {
+		if p.Dir != "" {
+			roots = append(roots, p.Dir)
+		}
+	}
+	return roots
+}
+
+// underTraceRoot reports whether path lies within one of the trace roots
+func (c *config) underTraceRoot(path string) bool {
+	for _, root := range c.traceRoots() {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// config returns the configuration in effect, it is replaced as a whole on reload
+func (s *Server) config() *config {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
+// reload reads the configuration again and keeps the running one when it is
+// invalid. Streams in progress go on with the settings they started with.
+func (s *Server) reload() {
+	c, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("configuration not reloaded: %v\n", err)
+		return
+	}
+	old := s.config()
+	if c.Listen != old.Listen || c.TLS != old.TLS || c.Auth != old.Auth {
+		fmt.Println("listener, TLS and auth settings only change on restart")
+		c.Listen, c.TLS, c.Auth = old.Listen, old.TLS, old.Auth
+	}
+	s.cfgMu.Lock()
+	s.cfg = c
+	s.cfgMu.Unlock()
+	s.endpoints.SetTimeouts(c.Sessions.IdleAfter, c.Sessions.ExpireAfter)
+	fmt.Println("configuration reloaded")
+}
+
+// reloadOnHangup reloads the configuration whenever the process gets SIGHUP
+func (s *Server) reloadOnHangup() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		s.reload()
+	}
+}
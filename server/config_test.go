@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		valid bool
+	}{
+		{"empty", "", true},
+		{"storage", "storage:\n  format: pcap\n  compression: zstd\n  rotate:\n    size_mb: 10\n", true},
+		{"unknown field", "storage:\n  fromat: pcap\n", false},
+		{"unknown format", "storage:\n  format: erf\n", false},
+		{"unknown layout field", "storage:\n  layout: \"{root}/{user}\"\n", false},
+		{"zero snaplen", "storage:\n  snaplen: 0\n", false},
+		{"negative rotation", "storage:\n  rotate:\n    packets: -1\n", false},
+		{"unknown compression", "storage:\n  compression: lz4\n", false},
+		{"negative retention", "retention:\n  keep_last: -1\n", false},
+		{"zero retention interval", "retention:\n  interval: 0s\n", false},
+		{"zero session timeout", "sessions:\n  idle_after: 0s\n", false},
+		{"compressors", "compressors: [zstd, gzip]\n", true},
+		{"unknown compressor", "compressors: [lz4]\n", false},
+		{"negative max rate", "max_rate: -1\n", false},
+		{"bad hostname pattern", "endpoints:\n  - hostname: \"[\"\n", false},
+		{"bad network", "endpoints:\n  - network: 10.0.0.0/33\n", false},
+		{"policy retention without dir", "endpoints:\n  - hostname: a\n    retention:\n      keep_last: 1\n", false},
+		{"policy retention", "endpoints:\n  - hostname: a\n    dir: /x\n    retention:\n      keep_last: 1\n", true},
+		{"bad policy compression", "endpoints:\n  - hostname: a\n    compression: lz4\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "server.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadConfig(path); (err == nil) != tt.valid {
+				t.Errorf("loadConfig = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConfigPolicies(t *testing.T) {
+	c := defaultConfig()
+	c.Storage.Dir = "/data"
+	c.Compressors = []string{"gzip", "snappy"}
+	c.MaxRate = 1000
+	c.Filters = []string{"not port 22"}
+	c.Endpoints = []endpointPolicy{
+		{Hostname: "lab-*", Deny: true},
+		{Hostname: "dc-*", Compressors: []string{}},
+		{Network: "10.20.0.0/16", Dir: "/branch", Format: formatPcap, Compression: compressZstd, MaxRate: 2000, Filters: []string{"tcp"}},
+	}
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		e           endpoint
+		deny        bool
+		dir         string
+		format      string
+		compression string
+		compressors []string
+		rate        int64
+		filters     []string
+	}{
+		{
+			name:        "no policy",
+			e:           endpoint{Hostname: "web01", IPAddress: "192.0.2.1"},
+			dir:         "/data",
+			format:      c.Storage.Format,
+			compressors: []string{"gzip", "snappy"},
+			rate:        1000,
+			filters:     []string{"not port 22"},
+		},
+		{
+			name:        "denied",
+			e:           endpoint{Hostname: "lab-3", IPAddress: "10.20.0.1"},
+			deny:        true,
+			dir:         "/data",
+			format:      c.Storage.Format,
+			compressors: []string{"gzip", "snappy"},
+			rate:        1000,
+			filters:     []string{"not port 22"},
+		},
+		{
+			name:        "no compressors",
+			e:           endpoint{Hostname: "dc-1", IPAddress: "192.0.2.1"},
+			dir:         "/data",
+			format:      c.Storage.Format,
+			compressors: []string{},
+			rate:        1000,
+			filters:     []string{"not port 22"},
+		},
+		{
+			name:        "network by peer address",
+			e:           endpoint{Hostname: "branch7", IPAddress: "192.0.2.1", Peer: "10.20.3.4"},
+			dir:         "/branch",
+			format:      formatPcap,
+			compression: compressZstd,
+			compressors: []string{"gzip", "snappy"},
+			rate:        2000,
+			filters:     []string{"not port 22", "tcp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p := c.policy(tt.e.Hostname, tt.e.address()); p.Deny != tt.deny {
+				t.Errorf("deny %v, want %v", p.Deny, tt.deny)
+			}
+			storage := c.storageFor(tt.e)
+			if storage.Dir != tt.dir || storage.Format != tt.format || storage.Compression != tt.compression {
+				t.Errorf("storage %s %s %q, want %s %s %q", storage.Dir, storage.Format, storage.Compression, tt.dir, tt.format, tt.compression)
+			}
+			if got := c.compressorsFor(tt.e); !reflect.DeepEqual(got, tt.compressors) {
+				t.Errorf("compressors %v, want %v", got, tt.compressors)
+			}
+			rate, filters := c.limitsFor(tt.e)
+			if rate != tt.rate || !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("limits %d %v, want %d %v", rate, filters, tt.rate, tt.filters)
+			}
+		})
+	}
+}
+
+func TestUnderTraceRoot(t *testing.T) {
+	c := defaultConfig()
+	c.Storage.Dir = filepath.FromSlash("/data")
+	c.Endpoints = []endpointPolicy{{Hostname: "a", Dir: filepath.FromSlash("/branch")}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/data/h/t.pcap", true},
+		{"/branch/t.pcap", true},
+		{"/data/../etc/passwd", false},
+		{"/database/t.pcap", false},
+		{"/etc/passwd", false},
+	}
+	for _, tt := range tests {
+		if got := c.underTraceRoot(filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("underTraceRoot(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// controlHub routes commands to the agents connected on the Control stream
+type controlHub struct {
+	mu     sync.Mutex
+	agents map[string]*agentConn
+	nextID uint64
+}
+
+// agentConn is the Control stream of one agent
+type agentConn struct {
+	commands chan *service.Command
+	mu       sync.Mutex
+	pending  map[uint64]chan *service.CommandReply
+}
+
+func newControlHub() *controlHub {
+	return &controlHub{agents: make(map[string]*agentConn)}
+}
+
+// attach registers the control stream of sessionID, replacing an older one
+func (h *controlHub) attach(sessionID string) *agentConn {
+	a := &agentConn{
+		commands: make(chan *service.Command),
+		pending:  make(map[uint64]chan *service.CommandReply),
+	}
+	h.mu.Lock()
+	h.agents[sessionID] = a
+	h.mu.Unlock()
+	return a
+}
+
+// detach forgets the control stream of sessionID unless it was already
+// replaced, it reports whether it did
+func (h *controlHub) detach(sessionID string, a *agentConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.agents[sessionID] != a {
+		return false
+	}
+	delete(h.agents, sessionID)
+	return true
+}
+
+// Connected reports whether sessionID has an open control stream
+func (h *controlHub) Connected(sessionID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.agents[sessionID]
+	return ok
+}
+
+// Send delivers cmd to the agent of sessionID and waits up to timeout for its reply
+func (h *controlHub) Send(sessionID string, cmd *service.Command, timeout time.Duration) (*service.CommandReply, error) {
+	h.mu.Lock()
+	a, ok := h.agents[sessionID]
+	h.nextID++
+	cmd.ID = h.nextID
+	h.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("session %s has no control stream", sessionID)
+	}
+
+	replies := make(chan *service.CommandReply, 1)
+	a.mu.Lock()
+	a.pending[cmd.ID] = replies
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.pending, cmd.ID)
+		a.mu.Unlock()
+	}()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	select {
+	case a.commands <- cmd:
+	case <-deadline.C:
+		return nil, fmt.Errorf("timed out sending %s to session %s", cmd.Type, sessionID)
+	}
+	select {
+	case r := <-replies:
+		return r, nil
+	case <-deadline.C:
+		return nil, fmt.Errorf("timed out waiting for session %s to acknowledge %s", sessionID, cmd.Type)
+	}
+}
+
+// deliver hands r to whoever waits for it and reports whether anyone did
+func (a *agentConn) deliver(r *service.CommandReply) bool {
+	a.mu.Lock()
+	replies, ok := a.pending[r.ID]
+	a.mu.Unlock()
+	if !ok {
+		return false
+	}
+	// a second reply to the same command finds the channel full and is dropped
+	select {
+	case replies <- r:
+	default:
+	}
+	return true
+}
+
+// Control keeps the command stream of an agent open until either side closes it
+func (s *Server) Control(srv service.RemoteCaputre_ControlServer) error {
+	ctx := srv.Context()
+	session, err := s.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint, Found := s.endpoints.Lookup(session)
+	if !Found || endpoint.State == stateExpired {
+		return status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+	}
+
+	agent := s.control.attach(session)
+	s.endpoints.SetControlled(session, true)
+	// a stream replaced by a newer one leaves the endpoint controlled
+	defer func() {
+		if s.control.detach(session, agent) {
+			s.endpoints.SetControlled(session, false)
+		}
+	}()
+	fmt.Printf("%s is waiting for commands\n", endpoint.Hostname)
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			r, err := srv.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if !agent.deliver(r) && r.Error != "" {
+				fmt.Printf("%s reported: %s\n", endpoint.Hostname, r.Error)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case cmd := <-agent.commands:
+			if err := srv.Send(cmd); err != nil {
+				return err
+			}
+		case err := <-recvErr:
+			fmt.Printf("%s stopped waiting for commands\n", endpoint.Hostname)
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package main
+
+import "errors"
+
+// diskFree is not supported here, the low disk watermark is not enforced
+func diskFree(path string) (int64, error) {
+	return 0, errors.New("free disk space is unknown on this platform")
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// enrollToken is handed out by operators, agents exchange it in GetReady for
+// an agent credential. Only the hash of its secret is kept.
+type enrollToken struct {
+	ID      string
+	Comment string `json:",omitempty"`
+	Created time.Time
+	// Expires is zero for tokens that never expire
+	Expires time.Time
+	// Uses is how many agents may enroll with the token, 0 for any number
+	Uses int
+	Used int
+	// Hostname limits the token to agents of that hostname, only such tokens
+	// enroll a revoked hostname again
+	Hostname string `json:",omitempty"`
+	Hash     string `json:"-"`
+}
+
+// enrolledAgent holds the credential issued to an agent, it is valid until revoked
+type enrolledAgent struct {
+	ID       string
+	Hostname string
+	Token    string
+	Enrolled time.Time
+	Hash     string `json:"-"`
+}
+
+// revokedAgent is kept for the hostname of a revoked agent, so that it can't
+// enroll again with any token that happens to be around
+type revokedAgent struct {
+	ID       string
+	Hostname string
+	Revoked  time.Time
+}
+
+// enrollment keeps the tokens and agent credentials in a JSON file, so agents
+// stay enrolled across collector restarts. Tokens and credentials are both
+// presented as ID.secret. It is safe for concurrent use.
+type enrollment struct {
+	mu     sync.Mutex
+	path   string
+	tokens map[string]*enrollToken
+	agents map[string]*enrolledAgent
+	// revoked is keyed by hostname
+	revoked map[string]*revokedAgent
+}
+
+// enrollmentFile is the layout of the file, unlike the admin API it has the hashes
+type enrollmentFile struct {
+	Tokens  []storedToken
+	Agents  []storedAgent
+	Revoked []revokedAgent
+}
+
+type storedToken struct {
+	enrollToken
+	Hash string
+}
+
+type storedAgent struct {
+	enrolledAgent
+	Hash string
+}
+
+// openEnrollment loads the enrollment file at path, a missing file is created
+// once the first token is
+func openEnrollment(path string) (*enrollment, error) {
+	e := &enrollment{
+		path:    path,
+		tokens:  make(map[string]*enrollToken),
+		agents:  make(map[string]*enrolledAgent),
+		revoked: make(map[string]*revokedAgent),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f enrollmentFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, t := range f.Tokens {
+		token := t.enrollToken
+		token.Hash = t.Hash
+		e.tokens[token.ID] = &token
+	}
+	for _, a := range f.Agents {
+		agent := a.enrolledAgent
+		agent.Hash = a.Hash
+		e.agents[agent.ID] = &agent
+	}
+	for i := range f.Revoked {
+		e.revoked[f.Revoked[i].Hostname] = &f.Revoked[i]
+	}
+	return e, nil
+}
+
+// save writes the file under a temporary name first so that a crash never leaves it half written
+func (e *enrollment) save() error {
+	var f enrollmentFile
+	for _, t := range e.tokens {
+		f.Tokens = append(f.Tokens, storedToken{*t, t.Hash})
+	}
+	for _, a := range e.agents {
+		f.Agents = append(f.Agents, storedAgent{*a, a.Hash})
+	}
+	for _, r := range e.revoked {
+		f.Revoked = append(f.Revoked, *r)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.path)
+}
+
+// newSecret returns a random ID and secret, and the secret's hash
+func newSecret() (id string, secret string, hash string, err error) {
+	if id, err = newSessionID(); err != nil {
+		return "", "", "", err
+	}
+	if secret, err = newSessionID(); err != nil {
+		return "", "", "", err
+	}
+	return id[:12], secret, hashSecret(secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitSecret splits an ID.secret string
+func splitSecret(s string) (id string, secret string, ok bool) {
+	i := strings.IndexByte(s, '.')
+	if i <= 0 || i == len(s)-1 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
+
+func secretMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(hash)) == 1
+}
+
+// CreateToken returns a new token for uses enrollments, 0 for any number,
+// valid for ttl or forever when ttl is 0, for agents of hostname unless it is
+// empty. The token string is only known to the caller.
+func (e *enrollment) CreateToken(uses int, ttl time.Duration, hostname string, comment string) (enrollToken, string, error) {
+	id, secret, hash, err := newSecret()
+	if err != nil {
+		return enrollToken{}, "", err
+	}
+	t := &enrollToken{
+		ID:       id,
+		Comment:  comment,
+		Created:  time.Now(),
+		Uses:     uses,
+		Hostname: hostname,
+		Hash:     hash,
+	}
+	if ttl > 0 {
+		t.Expires = t.Created.Add(ttl)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tokens[id] = t
+	if err := e.save(); err != nil {
+		delete(e.tokens, id)
+		return enrollToken{}, "", err
+	}
+	return *t, id + "." + secret, nil
+}
+
+// RevokeToken deletes a token, agents that enrolled with it keep their credentials
+func (e *enrollment) RevokeToken(id string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	t, ok := e.tokens[id]
+	if !ok {
+		return false, nil
+	}
+	delete(e.tokens, id)
+	if err := e.save(); err != nil {
+		e.tokens[id] = t
+		return false, err
+	}
+	return true, nil
+}
+
+// Enroll exchanges token for the credential of a new agent. Hostnames whose
+// agent was revoked need a token created for them.
+func (e *enrollment) Enroll(token string, hostname string) (string, string, error) {
+	id, secret, ok := splitSecret(token)
+	if !ok {
+		return "", "", fmt.Errorf("malformed enrollment token")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	t, ok := e.tokens[id]
+	switch {
+	case !ok || !secretMatches(secret, t.Hash):
+		return "", "", fmt.Errorf("unknown or revoked enrollment token")
+	case !t.Expires.IsZero() && time.Now().After(t.Expires):
+		return "", "", fmt.Errorf("enrollment token %s expired", id)
+	case t.Uses > 0 && t.Used >= t.Uses:
+		return "", "", fmt.Errorf("enrollment token %s is used up", id)
+	case t.Hostname != "" && t.Hostname != hostname:
+		return "", "", fmt.Errorf("enrollment token %s is for %s", id, t.Hostname)
+	}
+	revoked, wasRevoked := e.revoked[hostname]
+	if wasRevoked && t.Hostname == "" {
+		return "", "", fmt.Errorf("agent %s of %s was revoked, it needs a token created for its hostname", revoked.ID, hostname)
+	}
+
+	agentID, agentSecret, hash, err := newSecret()
+	if err != nil {
+		return "", "", err
+	}
+	e.agents[agentID] = &enrolledAgent{
+		ID:       agentID,
+		Hostname: hostname,
+		Token:    id,
+		Enrolled: time.Now(),
+		Hash:     hash,
+	}
+	t.Used++
+	delete(e.revoked, hostname)
+	if err := e.save(); err != nil {
+		delete(e.agents, agentID)
+		t.Used--
+		if wasRevoked {
+			e.revoked[hostname] = revoked
+		}
+		return "", "", err
+	}
+	return agentID, agentID + "." + agentSecret, nil
+}
+
+// Verify returns the agent credential belongs to
+func (e *enrollment) Verify(credential string) (string, bool) {
+	id, secret, ok := splitSecret(credential)
+	if !ok {
+		return "", false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	a, ok := e.agents[id]
+	if !ok || !secretMatches(secret, a.Hash) {
+		return "", false
+	}
+	return id, true
+}
+
+// Enrolled reports whether the credential of agent is still valid
+func (e *enrollment) Enrolled(agent string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.agents[agent]
+	return ok
+}
+
+// RevokeAgent invalidates the credential of an agent, its hostname can only
+// enroll again with a token created for it
+func (e *enrollment) RevokeAgent(id string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	a, ok := e.agents[id]
+	if !ok {
+		return false, nil
+	}
+	previous := e.revoked[a.Hostname]
+	delete(e.agents, id)
+	e.revoked[a.Hostname] = &revokedAgent{ID: id, Hostname: a.Hostname, Revoked: time.Now()}
+	if err := e.save(); err != nil {
+		e.agents[id] = a
+		if previous != nil {
+			e.revoked[a.Hostname] = previous
+		} else {
+			delete(e.revoked, a.Hostname)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Tokens returns all tokens ordered by creation time
+func (e *enrollment) Tokens() []enrollToken {
+	e.mu.Lock()
+	list := make([]enrollToken, 0, len(e.tokens))
+	for _, t := range e.tokens {
+		list = append(list, *t)
+	}
+	e.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list
+}
+
+// Revoked returns the revoked agents ordered by revocation time, one per hostname
+func (e *enrollment) Revoked() []revokedAgent {
+	e.mu.Lock()
+	list := make([]revokedAgent, 0, len(e.revoked))
+	for _, r := range e.revoked {
+		list = append(list, *r)
+	}
+	e.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Revoked.Before(list[j].Revoked)
+	})
+	return list
+}
+
+// Agents returns all enrolled agents ordered by enrollment time
+func (e *enrollment) Agents() []enrolledAgent {
+	e.mu.Lock()
+	list := make([]enrolledAgent, 0, len(e.agents))
+	for _, a := range e.agents {
+		list = append(list, *a)
+	}
+	e.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Enrolled.Before(list[j].Enrolled)
+	})
+	return list
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnroll(t *testing.T) {
+	tests := []struct {
+		name string
+		// uses, ttl and hostname of the token, and the hostname enrolling
+		uses     int
+		ttl      time.Duration
+		token    string
+		hostname string
+		// revoke an earlier agent of hostname first
+		revoked bool
+		valid   bool
+	}{
+		{name: "token", uses: 1, hostname: "web01", valid: true},
+		{name: "token for the hostname", uses: 1, token: "web01", hostname: "web01", valid: true},
+		{name: "token for another hostname", uses: 1, token: "web02", hostname: "web01"},
+		{name: "expired", uses: 1, ttl: time.Nanosecond, hostname: "web01"},
+		{name: "revoked hostname", uses: 1, hostname: "web01", revoked: true},
+		{name: "revoked hostname with a token for it", uses: 1, token: "web01", hostname: "web01", revoked: true, valid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := openEnrollment(filepath.Join(t.TempDir(), "auth.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoked {
+				_, token, err := e.CreateToken(1, 0, "", "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				agent, _, err := e.Enroll(token, tt.hostname)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok, err := e.RevokeAgent(agent); !ok || err != nil {
+					t.Fatalf("revoke %s: %v %v", agent, ok, err)
+				}
+			}
+			_, token, err := e.CreateToken(tt.uses, tt.ttl, tt.token, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(tt.ttl)
+			agent, credential, err := e.Enroll(token, tt.hostname)
+			if (err == nil) != tt.valid {
+				t.Fatalf("enroll = %v, want valid %v", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if got, ok := e.Verify(credential); !ok || got != agent {
+				t.Errorf("credential verified as %q %v, want %s", got, ok, agent)
+			}
+			if len(e.Revoked()) != 0 {
+				t.Errorf("%s is still revoked after enrolling again", tt.hostname)
+			}
+		})
+	}
+}
+
+func TestEnrollmentTokenUses(t *testing.T) {
+	e, err := openEnrollment(filepath.Join(t.TempDir(), "auth.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := e.CreateToken(2, 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hostname := range []string{"a", "b"} {
+		if _, _, err := e.Enroll(token, hostname); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := e.Enroll(token, "c"); err == nil {
+		t.Error("enrolled a third agent with a token for two")
+	}
+	if _, _, err := e.Enroll("nodot", "c"); err == nil {
+		t.Error("enrolled with a malformed token")
+	}
+}
+
+func TestEnrollmentKeepsRevocations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	e, err := openEnrollment(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := e.CreateToken(0, 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, credential, err := e.Enroll(token, "web01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RevokeAgent(agent); err != nil {
+		t.Fatal(err)
+	}
+
+	// after a restart
+	e, err = openEnrollment(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := e.Verify(credential); ok {
+		t.Error("revoked credential verified")
+	}
+	if _, _, err := e.Enroll(token, "web01"); err == nil {
+		t.Error("revoked hostname enrolled again with a token for any hostname")
+	}
+	if _, _, err := e.Enroll(token, "web02"); err != nil {
+		t.Errorf("other hostname: %v", err)
+	}
+	revoked := e.Revoked()
+	if len(revoked) != 1 || revoked[0].ID != agent || revoked[0].Hostname != "web01" {
+		t.Errorf("revoked %+v, want %s of web01", revoked, agent)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultLayout keeps the traces of every endpoint in a directory per day
+const defaultLayout = "{root}/{hostname}/{date}/{hostname}_{time}_{session}"
+
+// maxNameLength bounds the length of a client supplied value in a path
+const maxNameLength = 64
+
+var layoutField = regexp.MustCompile(`\{[a-z]+\}`)
+
+// layoutFields are the placeholders a layout may use
+var layoutFields = map[string]bool{
+	"{root}":     true,
+	"{hostname}": true,
+	"{ip}":       true,
+	"{os}":       true,
+	"{session}":  true,
+	"{date}":     true,
+	"{time}":     true,
+	"{segment}":  true,
+}
+
+// reservedNames can't be used as file names on Windows, whatever their extension
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+func validLayout(layout string) error {
+	if layout == "" {
+		return fmt.Errorf("empty storage layout")
+	}
+	for _, field := range layoutField.FindAllString(layout, -1) {
+		if !layoutFields[field] {
+			return fmt.Errorf("unknown field %s in storage layout %q", field, layout)
+		}
+	}
+	return nil
+}
+
+// tracePath expands the storage layout for segment n of a stream of e, started
+// at t, the extension of the trace format is left to openTrace. Dates and times
+// are UTC. Rotated streams number their segments at the end of the name,
+// unless the layout places {segment} elsewhere.
+func tracePath(storage storageConfig, e endpoint, t time.Time, n int) string {
+	layout := storage.Layout
+	if storage.Rotate.enabled() && !strings.Contains(layout, "{segment}") {
+		layout += "_{segment}"
+	}
+	t = t.UTC()
+	fields := map[string]string{
+		"{root}":     storage.Dir,
+		"{hostname}": safeName(e.Hostname),
+		"{ip}":       safeName(e.IPAddress),
+		"{os}":       safeName(e.OS),
+		"{session}":  safeName(e.SessionID),
+		"{date}":     t.Format("2006-01-02"),
+		"{time}":     t.Format("150405"),
+		"{segment}":  fmt.Sprintf("%04d", n),
+	}
+	path := layoutField.ReplaceAllStringFunc(layout, func(field string) string {
+		return fields[field]
+	})
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+// safeName turns a value supplied by a client into a single path element that
+// is valid on any OS. Everything but letters, digits, dots, dashes and
+// underscores is replaced, so separators can't climb out of the layout.
+func safeName(s string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	// no . or .. and no hidden files, Windows drops trailing dots
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "unknown"
+	}
+	if base := strings.ToUpper(strings.SplitN(name, ".", 2)[0]); reservedNames[base] {
+		name = "_" + name
+	}
+	return name
+}
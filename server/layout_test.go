@@ -0,0 +1,113 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracePath(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+	e := endpoint{Hostname: "web01", IPAddress: "192.0.2.1", OS: "linux", SessionID: "ab12"}
+	tests := []struct {
+		name    string
+		layout  string
+		rotate  rotateConfig
+		e       endpoint
+		segment int
+		want    string
+	}{
+		{
+			name:   "default",
+			layout: defaultLayout,
+			e:      e,
+			want:   "/data/web01/2026-03-04/web01_040607_ab12",
+		},
+		{
+			name:   "all fields",
+			layout: "{root}/{os}/{ip}/{date}/{time}_{session}",
+			e:      e,
+			want:   "/data/linux/192.0.2.1/2026-03-04/040607_ab12",
+		},
+		{
+			name:    "rotated streams number segments at the end",
+			layout:  defaultLayout,
+			rotate:  rotateConfig{Packets: 10},
+			e:       e,
+			segment: 3,
+			want:    "/data/web01/2026-03-04/web01_040607_ab12_0003",
+		},
+		{
+			name:    "segment placed by the layout",
+			layout:  "{root}/{hostname}/{segment}/{session}",
+			rotate:  rotateConfig{Packets: 10},
+			e:       e,
+			segment: 12,
+			want:    "/data/web01/0012/ab12",
+		},
+		{
+			name:   "separators in client values",
+			layout: "{root}/{hostname}/{session}",
+			e:      endpoint{Hostname: "../../etc", SessionID: `a\b/c`},
+			want:   "/data/_.._etc/a_b_c",
+		},
+		{
+			name:   "empty client values",
+			layout: "{root}/{hostname}/{os}",
+			e:      endpoint{Hostname: "..", OS: ""},
+			want:   "/data/unknown/unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := storageConfig{Dir: "/data", Layout: tt.layout, Rotate: tt.rotate}
+			got := tracePath(storage, tt.e, at, tt.segment)
+			if want := filepath.FromSlash(tt.want); got != want {
+				t.Errorf("tracePath = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"web01", "web01"},
+		{"web01.example.com", "web01.example.com"},
+		{"fe80::1%eth0", "fe80__1_eth0"},
+		{"..", "unknown"},
+		{".hidden", "hidden"},
+		{"trailing.", "trailing"},
+		{"", "unknown"},
+		{"CON", "_CON"},
+		{"com1.example", "_com1.example"},
+		{"console", "console"},
+		{strings.Repeat("a", 100), strings.Repeat("a", maxNameLength)},
+	}
+	for _, tt := range tests {
+		if got := safeName(tt.in); got != tt.want {
+			t.Errorf("safeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		valid  bool
+	}{
+		{defaultLayout, true},
+		{"{root}/{segment}", true},
+		{"", false},
+		{"{root}/{user}", false},
+		{"{root}/static", true},
+	}
+	for _, tt := range tests {
+		if err := validLayout(tt.layout); (err == nil) != tt.valid {
+			t.Errorf("validLayout(%q) = %v, want valid %v", tt.layout, err, tt.valid)
+		}
+	}
+}
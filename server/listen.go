@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// listen opens a TCP listener on addr, host:port or [IPv6 address]:port, or a
+// unix socket for unix:/path
+func listen(addr string) (net.Listener, error) {
+	path, ok := unixSocketPath(addr)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	// a socket left behind by an earlier run would make Listen fail, anything
+	// else at path is not ours to remove
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// unixSocketPath returns the path of a unix:/path or unix:///path address
+func unixSocketPath(addr string) (string, bool) {
+	if strings.HasPrefix(addr, "unix://") {
+		return strings.TrimPrefix(addr, "unix://"), true
+	}
+	if strings.HasPrefix(addr, "unix:") {
+		return strings.TrimPrefix(addr, "unix:"), true
+	}
+	return "", false
+}
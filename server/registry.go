@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+// endpointState is where an endpoint is in its lifecycle
+type endpointState int
+
+const (
+	// stateRegistered endpoints called GetReady but never opened a stream
+	stateRegistered endpointState = iota
+	// stateStreaming endpoints have a stream that delivered packets recently
+	stateStreaming
+	// stateIdle endpoints have an open stream that went quiet
+	stateIdle
+	// stateDisconnected endpoints had all their streams closed
+	stateDisconnected
+	// stateExpired endpoints were not seen for too long, their session is no longer accepted
+	stateExpired
+)
+
+func (s endpointState) String() string {
+	switch s {
+	case stateRegistered:
+		return "registered"
+	case stateStreaming:
+		return "streaming"
+	case stateIdle:
+		return "idle"
+	case stateDisconnected:
+		return "disconnected"
+	case stateExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("endpointState(%d)", int(s))
+}
+
+// MarshalText encodes the state by name
+func (s endpointState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// captureStats are the latest libpcap counters a client reported for one
+// interface, packets dropped there never reached the trace
+type captureStats struct {
+	Received  int64
+	Dropped   int64
+	IfDropped int64
+	Captured  int64
+	Updated   time.Time
+}
+
+// captureInterface is an interface of the capture a client described, Snaplen
+// is 0 when the client did not tell
+type captureInterface struct {
+	Name     string
+	LinkType layers.LinkType
+	Snaplen  uint32
+	Filter   string
+}
+
+// endpoint is a registered client and the capture it described last
+type endpoint struct {
+	SessionID   string
+	Hostname    string
+	IPAddress   string
+	Peer        string
+	OS          string
+	Identity    string
+	Agent       string
+	Instance    string
+	Interfaces  []captureInterface
+	Packetcount int
+	Sequence    sequenceStats
+	Captures    map[string]captureStats
+	State       endpointState
+	Registered  time.Time
+	LastSeen    time.Time
+	Controlled  bool
+	// Protocol is the version of the v1 API the endpoint registered with, 0
+	// for the legacy service, and Capabilities those agreed on
+	Protocol     uint32
+	Capabilities []string
+	streams      int `json:"-"`
+}
+
+// registry tracks the endpoints registered through GetReady, keyed by session ID.
+// It is safe for concurrent use, readers always get copies of the endpoints.
+type registry struct {
+	mu          sync.RWMutex
+	endpoints   map[string]*endpoint
+	idleAfter   time.Duration
+	expireAfter time.Duration
+}
+
+// newRegistry returns a registry marking streams idle after idleAfter without
+// packets and expiring endpoints not seen for expireAfter.
+func newRegistry(idleAfter, expireAfter time.Duration) *registry {
+	return &registry{
+		endpoints:   make(map[string]*endpoint),
+		idleAfter:   idleAfter,
+		expireAfter: expireAfter,
+	}
+}
+
+// SetTimeouts replaces the idle and expiry timeouts from the next sweep on
+func (r *registry) SetTimeouts(idleAfter, expireAfter time.Duration) {
+	r.mu.Lock()
+	r.idleAfter, r.expireAfter = idleAfter, expireAfter
+	r.mu.Unlock()
+}
+
+// Register adds e in the registered state. A new session of a client
+// instance known from an earlier session goes on with its packet sequence.
+func (r *registry) Register(e endpoint) {
+	now := time.Now()
+	e.State = stateRegistered
+	e.Registered = now
+	e.LastSeen = now
+	e.streams = 0
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous := r.lastSession(e); previous != nil {
+		e.Sequence = previous.Sequence.cursor()
+	}
+	r.endpoints[e.SessionID] = &e
+}
+
+// lastSession returns the latest session of the client instance of e, nil
+// when there is none. Sessions must agree on who the client is as well, so
+// an instance ID alone can't take over the sequence of another client.
+func (r *registry) lastSession(e endpoint) *endpoint {
+	if e.Instance == "" {
+		return nil
+	}
+	var last *endpoint
+	for _, old := range r.endpoints {
+		if old.Instance != e.Instance || old.Hostname != e.Hostname ||
+			old.Identity != e.Identity || old.Agent != e.Agent {
+			continue
+		}
+		if last == nil || old.Registered.After(last.Registered) {
+			last = old
+		}
+	}
+	return last
+}
+
+// address returns the address e connected from, or the one it reported when
+// that is unknown
+func (e endpoint) address() string {
+	if e.Peer != "" {
+		return e.Peer
+	}
+	return e.IPAddress
+}
+
+// Lookup returns the endpoint registered under sessionID
+func (r *registry) Lookup(sessionID string) (endpoint, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok {
+		return endpoint{}, false
+	}
+	return *e, true
+}
+
+// Endpoints returns all known endpoints ordered by registration time
+func (r *registry) Endpoints() []endpoint {
+	r.mu.RLock()
+	list := make([]endpoint, 0, len(r.endpoints))
+	for _, e := range r.endpoints {
+		list = append(list, *e)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Registered.Before(list[j].Registered)
+	})
+	return list
+}
+
+// InState returns the endpoints currently in state
+func (r *registry) InState(state endpointState) []endpoint {
+	var list []endpoint
+	for _, e := range r.Endpoints() {
+		if e.State == state {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// SetCapture records the interfaces the endpoint streams from next, it fails
+// for unknown and expired sessions
+func (r *registry) SetCapture(sessionID string, interfaces []captureInterface) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok || e.State == stateExpired {
+		return false
+	}
+	e.Interfaces = interfaces
+	e.LastSeen = time.Now()
+	return true
+}
+
+// SetProtocol records the API version and capabilities the endpoint registered with
+func (r *registry) SetProtocol(sessionID string, version uint32, capabilities []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.endpoints[sessionID]; ok {
+		e.Protocol, e.Capabilities = version, capabilities
+	}
+}
+
+// SetControlled records whether the endpoint has an open Control stream,
+// controlled endpoints never expire
+func (r *registry) SetControlled(sessionID string, controlled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.endpoints[sessionID]; ok {
+		e.Controlled = controlled
+		e.LastSeen = time.Now()
+	}
+}
+
+// StreamStarted moves the endpoint to streaming, it fails for unknown and expired sessions
+func (r *registry) StreamStarted(sessionID string) (endpoint, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok || e.State == stateExpired {
+		return endpoint{}, false
+	}
+	e.streams++
+	e.State = stateStreaming
+	e.LastSeen = time.Now()
+	return *e, true
+}
+
+// StreamEnded moves the endpoint to disconnected once its last stream closed
+func (r *registry) StreamEnded(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok {
+		return
+	}
+	e.streams--
+	e.LastSeen = time.Now()
+	if e.streams <= 0 {
+		e.streams = 0
+		e.State = stateDisconnected
+	}
+}
+
+// AddPackets counts n received packets and returns the endpoint's new total
+func (r *registry) AddPackets(sessionID string, n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok {
+		return 0
+	}
+	e.Packetcount += n
+	e.LastSeen = time.Now()
+	if e.State == stateIdle {
+		e.State = stateStreaming
+	}
+	return e.Packetcount
+}
+
+// CheckSequence tracks the sequence number of a packet received from the
+// endpoint, see sequenceStats.check
+func (r *registry) CheckSequence(sessionID string, seq uint64) sequenceCheck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok {
+		return sequenceCheck{}
+	}
+	return e.Sequence.check(seq)
+}
+
+// SetCaptureStats records the counters reported for an interface of the
+// endpoint. It returns how many more packets were dropped since the last
+// report, a capture started over counts from zero again.
+func (r *registry) SetCaptureStats(sessionID string, interfaceName string, stats captureStats) (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.endpoints[sessionID]
+	if !ok || e.State == stateExpired {
+		return 0, false
+	}
+	previous := e.Captures[interfaceName]
+	if stats.Received < previous.Received {
+		previous = captureStats{}
+	}
+	// the map is replaced rather than updated, copies handed out share it
+	captures := make(map[string]captureStats, len(e.Captures)+1)
+	for name, c := range e.Captures {
+		captures[name] = c
+	}
+	captures[interfaceName] = stats
+	e.Captures = captures
+	e.LastSeen = time.Now()
+	return stats.Dropped + stats.IfDropped - previous.Dropped - previous.IfDropped, true
+}
+
+// Sweep applies idle and expiry timeouts as of now. Expired endpoints are kept
+// for another expiry period so their state can still be queried, then dropped.
+// It returns the endpoints that changed state.
+func (r *registry) Sweep(now time.Time) []endpoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var changed []endpoint
+	for id, e := range r.endpoints {
+		quiet := now.Sub(e.LastSeen)
+		switch e.State {
+		case stateStreaming:
+			if quiet < r.idleAfter {
+				continue
+			}
+			e.State = stateIdle
+		case stateRegistered, stateDisconnected:
+			if quiet < r.expireAfter || e.Controlled {
+				continue
+			}
+			e.State = stateExpired
+		case stateExpired:
+			if quiet >= 2*r.expireAfter {
+				delete(r.endpoints, id)
+			}
+			continue
+		default:
+			continue
+		}
+		changed = append(changed, *e)
+	}
+	return changed
+}
+
+// Run sweeps the registry every interval, it never returns
+func (r *registry) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, e := range r.Sweep(now) {
+			fmt.Printf("%s (%s) is %s\n", e.Hostname, e.SessionID, e.State)
+		}
+	}
+}
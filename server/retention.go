@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retentionConfig limits the traces kept under a directory, the oldest are
+// removed first. Zero values disable a limit.
+type retentionConfig struct {
+	MaxAge     time.Duration `yaml:"max_age"`
+	MaxTotalMB int64         `yaml:"max_total_mb"`
+	KeepLast   int           `yaml:"keep_last"`
+	// Interval is how often the janitor runs, it is only read from the global rules
+	Interval time.Duration `yaml:"interval"`
+}
+
+func (r retentionConfig) validate() error {
+	if r.MaxAge < 0 || r.MaxTotalMB < 0 || r.KeepLast < 0 {
+		return fmt.Errorf("retention limits can't be negative")
+	}
+	return nil
+}
+
+func (r retentionConfig) enabled() bool {
+	return r.MaxAge > 0 || r.MaxTotalMB > 0 || r.KeepLast > 0
+}
+
+// traceFile is a complete trace found by the janitor
+type traceFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// isTrace reports whether name is a complete trace, compressed or not. Traces
+// still being written keep their part suffix.
+func isTrace(name string) bool {
+	name = uncompressedName(name)
+	return strings.HasSuffix(name, ".pcap") || strings.HasSuffix(name, ".pcapng")
+}
+
+// janitor enforces the retention rules of the configuration in effect, it never returns
+func (s *Server) janitor() {
+	for {
+		c := s.config()
+		s.enforceRetention(c, time.Now())
+		time.Sleep(c.Retention.Interval)
+	}
+}
+
+// enforceRetention applies the global rules to the storage dir and the rules
+// of endpoint policies to their dirs
+func (s *Server) enforceRetention(c *config, now time.Time) {
+	var excluded []string
+	for _, p := range c.Endpoints {
+		if p.Retention == nil {
+			continue
+		}
+		excluded = append(excluded, absPath(p.Dir))
+		if p.Retention.enabled() {
+			removeExpiredTraces(p.Dir, *p.Retention, nil, now)
+		}
+	}
+	if c.Retention.enabled() {
+		removeExpiredTraces(c.Storage.Dir, c.Retention, excluded, now)
+	}
+	if low, free := lowOnDisk(c.Storage.Dir, c.Storage.MinFreeMB); low {
+		fmt.Printf("%s is low on disk space, %d MB free, new streams are rejected\n", c.Storage.Dir, free>>20)
+	}
+}
+
+// removeExpiredTraces removes the traces under root that rules don't keep,
+// along with their gap logs, skipping the excluded directories
+func removeExpiredTraces(root string, rules retentionConfig, excluded []string, now time.Time) {
+	var traces []traceFile
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if fi.IsDir() {
+			path = absPath(path)
+			for _, dir := range excluded {
+				if path == dir && path != absPath(root) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if isTrace(fi.Name()) {
+			traces = append(traces, traceFile{path: path, size: fi.Size(), modTime: fi.ModTime()})
+		}
+		return nil
+	})
+	// newest first
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].modTime.After(traces[j].modTime)
+	})
+
+	kept, removed := 0, 0
+	var total, freed int64
+	for _, t := range traces {
+		keep := true
+		switch {
+		case rules.MaxAge > 0 && now.Sub(t.modTime) > rules.MaxAge:
+			keep = false
+		case rules.KeepLast > 0 && kept >= rules.KeepLast:
+			keep = false
+		case rules.MaxTotalMB > 0 && total+t.size > rules.MaxTotalMB<<20:
+			keep = false
+		}
+		if keep {
+			kept++
+			total += t.size
+			continue
+		}
+		if err := os.Remove(t.path); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		os.Remove(t.path + ".gaps")
+		removeEmptyDirs(filepath.Dir(t.path), root)
+		removed++
+		freed += t.size
+	}
+	if removed > 0 {
+		fmt.Printf("retention removed %d traces, %d MB from %s\n", removed, freed>>20, root)
+	}
+}
+
+// absPath makes paths comparable whether they were configured relative or absolute
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// removeEmptyDirs removes dir and its parents up to root as long as they are empty
+func removeEmptyDirs(dir string, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root; dir = filepath.Dir(dir) {
+		if rel, err := filepath.Rel(root, dir); err != nil || strings.HasPrefix(rel, "..") {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// lowOnDisk reports whether dir has less than minFreeMB free, and how much it
+// has. It never reports low when the free space is unknown.
+func lowOnDisk(dir string, minFreeMB int64) (bool, int64) {
+	if minFreeMB <= 0 {
+		return false, 0
+	}
+	free, err := diskFree(dir)
+	if err != nil {
+		return false, 0
+	}
+	return free < minFreeMB<<20, free
+}
+
+// checkDiskSpace rejects new streams while the storage of their traces is low on space
+func checkDiskSpace(storage storageConfig) error {
+	if low, free := lowOnDisk(storage.Dir, storage.MinFreeMB); low {
+		return status.Errorf(codes.ResourceExhausted, "collector is low on disk space, %d MB free, retry later", free>>20)
+	}
+	return nil
+}
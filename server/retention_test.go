@@ -0,0 +1,154 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// traceTree creates the files of names under root, each one hour older than
+// the one before and one MB large
+func traceTree(t *testing.T, root string, now time.Time, names ...string) {
+	t.Helper()
+	for i, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, make([]byte, 1<<20), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-time.Duration(i+1) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// filesUnder lists the files under root, relative to it
+func filesUnder(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func equalFiles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRemoveExpiredTraces(t *testing.T) {
+	// newest first
+	names := []string{"h/d2/t1.pcapng", "h/d2/t2.pcap.gz", "h/d1/t3.pcap.zst", "h/d1/t4.pcap"}
+	tests := []struct {
+		name  string
+		rules retentionConfig
+		want  []string
+	}{
+		{
+			name:  "max age",
+			rules: retentionConfig{MaxAge: 150 * time.Minute},
+			want:  []string{"h/d2/t1.pcapng", "h/d2/t2.pcap.gz"},
+		},
+		{
+			name:  "keep last",
+			rules: retentionConfig{KeepLast: 3},
+			want:  []string{"h/d1/t3.pcap.zst", "h/d2/t1.pcapng", "h/d2/t2.pcap.gz"},
+		},
+		{
+			name:  "max total",
+			rules: retentionConfig{MaxTotalMB: 1},
+			want:  []string{"h/d2/t1.pcapng"},
+		},
+		{
+			name:  "strictest rule wins",
+			rules: retentionConfig{MaxAge: 150 * time.Minute, KeepLast: 3, MaxTotalMB: 3},
+			want:  []string{"h/d2/t1.pcapng", "h/d2/t2.pcap.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			now := time.Now()
+			traceTree(t, root, now, names...)
+			// gap logs go with their trace, files being written and other files stay
+			traceTree(t, root, now, "h/d1/t4.pcap.gaps", "h/d1/t5.pcap.part", "h/notes.txt")
+			tt.want = append(tt.want, "h/d1/t5.pcap.part", "h/notes.txt")
+			sort.Strings(tt.want)
+
+			removeExpiredTraces(root, tt.rules, nil, now)
+			if got := filesUnder(t, root); !equalFiles(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveExpiredTracesRemovesEmptyDirs(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	traceTree(t, root, now, "h/d2/new.pcap", "h/d1/old.pcap")
+	removeExpiredTraces(root, retentionConfig{KeepLast: 1}, nil, now)
+	if _, err := os.Stat(filepath.Join(root, "h", "d1")); !os.IsNotExist(err) {
+		t.Errorf("empty dir left: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "h", "d2", "new.pcap")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnforceRetentionExcludesPolicyDirs(t *testing.T) {
+	root := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := filepath.Rel(cwd, root)
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name       string
+		storageDir string
+		policyDir  string
+	}{
+		{"absolute", root, filepath.Join(root, "branch")},
+		{"relative storage dir", relative, filepath.Join(root, "branch")},
+		{"relative policy dir", root, filepath.Join(relative, "branch")},
+		{"unclean policy dir", root, filepath.Join(root, "x", "..", "branch") + string(filepath.Separator)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			traceTree(t, root, now, "h/t1.pcap", "branch/b1.pcap", "branch/b2.pcap")
+			c := defaultConfig()
+			c.Storage.Dir = tt.storageDir
+			c.Retention = retentionConfig{KeepLast: 1}
+			// the policy keeps its traces forever
+			c.Endpoints = []endpointPolicy{{Dir: tt.policyDir, Retention: &retentionConfig{}}}
+			(&Server{cfg: c}).enforceRetention(c, now)
+			want := []string{"branch/b1.pcap", "branch/b2.pcap", "h/t1.pcap"}
+			if got := filesUnder(t, root); !equalFiles(got, want) {
+				t.Errorf("kept %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// recordOverhead is about what a packet adds to a trace besides its data,
+// rotation by size counts it for every packet
+var recordOverhead = map[string]int64{
+	formatPcap:   16,
+	formatPcapng: 32,
+}
+
+// segments writes the packets of one stream to a series of traces, rotated as
+// the storage settings say, each with its gap log. A segment closed on time
+// is only followed by the next one once another packet arrives. It is safe
+// for concurrent use.
+type segments struct {
+	mu      sync.Mutex
+	storage storageConfig
+	e       endpoint
+	number  int
+	trace   *trace
+	gaps    *gapLog
+	opened  time.Time
+	// next wall clock boundary to rotate at
+	boundary time.Time
+	bytes    int64
+	packets  int
+	// stored and closed are what the whole stream wrote, closed the final
+	// names of the finished segments
+	stored      uint64
+	storedBytes uint64
+	closed      []string
+}
+
+// openSegments opens the first segment of a stream of e started at now
+func openSegments(storage storageConfig, e endpoint, now time.Time) (*segments, error) {
+	s := &segments{storage: storage, e: e}
+	if err := s.open(now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *segments) open(now time.Time) error {
+	path := tracePath(s.storage, s.e, now, s.number+1)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	t, err := openTrace(path, s.storage, s.e)
+	if err != nil {
+		return err
+	}
+	s.number++
+	s.trace, s.gaps = t, newGapLog(t.path)
+	s.opened, s.bytes, s.packets = now, 0, 0
+	if every := s.storage.Rotate.Every; every > 0 {
+		s.boundary = now.Truncate(every).Add(every)
+	}
+	return nil
+}
+
+// finish closes the current segment, its files get their final names
+func (s *segments) finish() error {
+	if s.trace == nil {
+		return nil
+	}
+	finished, err := s.trace.Close()
+	s.closed = append(s.closed, finished...)
+	if gerr := s.gaps.Close(); err == nil {
+		err = gerr
+	}
+	s.trace, s.gaps = nil, nil
+	return err
+}
+
+// expired reports whether the current segment is past its duration or a wall clock boundary
+func (s *segments) expired(now time.Time) bool {
+	r := s.storage.Rotate
+	return r.Duration > 0 && now.Sub(s.opened) >= r.Duration ||
+		!s.boundary.IsZero() && !now.Before(s.boundary)
+}
+
+// full reports whether the current segment reached its size or packet limit
+func (s *segments) full() bool {
+	r := s.storage.Rotate
+	return r.SizeMB > 0 && s.bytes >= r.SizeMB<<20 ||
+		r.Packets > 0 && s.packets >= r.Packets
+}
+
+// Ready makes sure a segment that can take another packet is open, rotating
+// the current one when it is full or expired. Gaps found before the packet go
+// to the gap log of the segment it is written to.
+func (s *segments) Ready(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.trace != nil && (s.full() || s.expired(now)) {
+		if err := s.finish(); err != nil {
+			return err
+		}
+	}
+	return s.current(now)
+}
+
+// current opens the next segment unless one is open, Tick may have closed it
+// since Ready
+func (s *segments) current(now time.Time) error {
+	if s.trace != nil {
+		return nil
+	}
+	return s.open(now)
+}
+
+// Tick closes the current segment once it expired, so that it is complete on
+// time even while the stream is quiet, and flushes it otherwise. Flushing on
+// the tick rather than per frame keeps compressors from sync flushing every
+// packet
+func (s *segments) Tick(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.trace == nil {
+		return nil
+	}
+	if !s.expired(now) {
+		return s.trace.Flush()
+	}
+	return s.finish()
+}
+
+// WritePacket writes to the current segment, Ready rotates it before
+func (s *segments) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.current(time.Now()); err != nil {
+		return err
+	}
+	s.packets++
+	s.bytes += int64(len(data)) + recordOverhead[s.storage.Format]
+	if err := s.trace.WritePacket(ci, data); err != nil {
+		return err
+	}
+	s.stored++
+	s.storedBytes += uint64(len(data))
+	return nil
+}
+
+// Missing, Late, Unmatched and Duplicate log to the gap log of the current segment
+func (s *segments) Missing(seq, n uint64) {
+	s.logGap(func(l *gapLog) { l.missing(seq, n) })
+}
+
+func (s *segments) Late(seq uint64) {
+	s.logGap(func(l *gapLog) { l.late(seq) })
+}
+
+func (s *segments) Unmatched(seq uint64) {
+	s.logGap(func(l *gapLog) { l.unmatched(seq) })
+}
+
+func (s *segments) Duplicate(seq uint64) {
+	s.logGap(func(l *gapLog) { l.duplicate(seq) })
+}
+
+func (s *segments) logGap(log func(*gapLog)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.current(time.Now()); err != nil {
+		fmt.Println(err)
+		return
+	}
+	log(s.gaps)
+}
+
+// Close finishes the last segment
+func (s *segments) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finish()
+}
+
+// Stored returns the packets and packet bytes written, and the names of the
+// finished segments
+func (s *segments) Stored() (uint64, uint64, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stored, s.storedBytes, append([]string(nil), s.closed...)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestSegmentsRotate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 59, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		rotate  rotateConfig
+		format  string
+		packets int
+		size    int
+		// step between packets
+		step time.Duration
+		want int
+	}{
+		{
+			name:    "no rotation",
+			format:  formatPcap,
+			packets: 10,
+			size:    100,
+			want:    1,
+		},
+		{
+			name:    "by packets",
+			rotate:  rotateConfig{Packets: 3},
+			format:  formatPcap,
+			packets: 7,
+			size:    100,
+			want:    3,
+		},
+		{
+			name:    "by size",
+			rotate:  rotateConfig{SizeMB: 1},
+			format:  formatPcapng,
+			packets: 40,
+			size:    60000,
+			want:    3,
+		},
+		{
+			name:    "by duration",
+			rotate:  rotateConfig{Duration: 10 * time.Second},
+			format:  formatPcap,
+			packets: 6,
+			size:    100,
+			step:    5 * time.Second,
+			want:    3,
+		},
+		{
+			name:    "on the clock",
+			rotate:  rotateConfig{Every: time.Minute},
+			format:  formatPcapng,
+			packets: 4,
+			size:    100,
+			step:    30 * time.Second,
+			want:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := storageConfig{Dir: t.TempDir(), Layout: defaultLayout, Format: tt.format, Snaplen: 65535, Rotate: tt.rotate}
+			s, err := openSegments(storage, endpoint{Hostname: "h", SessionID: "s"}, start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := make([]byte, tt.size)
+			now := start
+			for i := 0; i < tt.packets; i++ {
+				if err := s.Ready(now); err != nil {
+					t.Fatal(err)
+				}
+				ci := gopacket.CaptureInfo{Timestamp: now, CaptureLength: tt.size, Length: tt.size}
+				if err := s.WritePacket(ci, data); err != nil {
+					t.Fatal(err)
+				}
+				now = now.Add(tt.step)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			stored, bytes, closed := s.Stored()
+			if stored != uint64(tt.packets) || bytes != uint64(tt.packets*tt.size) {
+				t.Errorf("stored %d packets, %d bytes, want %d, %d", stored, bytes, tt.packets, tt.packets*tt.size)
+			}
+			if len(closed) != tt.want {
+				t.Fatalf("got %d segments %v, want %d", len(closed), closed, tt.want)
+			}
+			for _, path := range closed {
+				if _, err := os.Stat(path); err != nil {
+					t.Error(err)
+				}
+			}
+			if parts := partFiles(t, storage.Dir); len(parts) > 0 {
+				t.Errorf("part files left: %v", parts)
+			}
+		})
+	}
+}
+
+func TestSegmentsTick(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 59, 58, 0, time.UTC)
+	storage := storageConfig{Dir: t.TempDir(), Layout: defaultLayout, Format: formatPcapng, Snaplen: 65535, Rotate: rotateConfig{Every: time.Hour}}
+	s, err := openSegments(storage, endpoint{Hostname: "h", SessionID: "s"}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Tick(start.Add(time.Second))
+	if _, _, closed := s.Stored(); len(closed) != 0 {
+		t.Fatalf("closed %v before the boundary", closed)
+	}
+	s.Tick(start.Add(2 * time.Second))
+	if _, _, closed := s.Stored(); len(closed) != 1 {
+		t.Fatalf("closed %v on the boundary, want 1 segment", closed)
+	}
+	// the next segment only opens with the next packet
+	if parts := partFiles(t, storage.Dir); len(parts) != 0 {
+		t.Errorf("opened %v while idle", parts)
+	}
+}
+
+func TestSegmentsFlushOnTick(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	storage := storageConfig{Dir: t.TempDir(), Layout: "{root}/{session}", Format: formatPcap, Snaplen: 65535, Compression: compressGzip}
+	s, err := openSegments(storage, endpoint{SessionID: "s"}, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	size := func() int64 {
+		parts := partFiles(t, storage.Dir)
+		if len(parts) != 1 {
+			t.Fatalf("part files %v, want 1", parts)
+		}
+		fi, err := os.Stat(parts[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.Size()
+	}
+	for i := 0; i < 10; i++ {
+		if err := s.WritePacket(gopacket.CaptureInfo{Timestamp: start, CaptureLength: 100, Length: 100}, make([]byte, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	written := size()
+	if err := s.Tick(start.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if flushed := size(); flushed <= written {
+		t.Errorf("trace of %d bytes is %d bytes after the tick, want it flushed", written, flushed)
+	}
+}
+
+func TestOpenTraceRemovesPartFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "trace")
+	// the second file of a two interface pcap trace exists
+	if err := ioutil.WriteFile(base+"-if1.pcap", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := endpoint{Interfaces: []captureInterface{
+		{Name: "eth0", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+		{Name: "lo", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+	}}
+	if _, err := openTrace(base, storageConfig{Format: formatPcap, Snaplen: 65535}, e); err == nil {
+		t.Fatal("opened a trace over an existing one")
+	}
+	if _, err := os.Stat(base + "-if0.pcap"); !os.IsNotExist(err) {
+		t.Errorf("first file was finalized: %v", err)
+	}
+	if parts := partFiles(t, dir); len(parts) > 0 {
+		t.Errorf("part files left: %v", parts)
+	}
+}
+
+// failingWriter fails to flush
+type failingWriter struct{}
+
+func (failingWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return nil
+}
+
+func (failingWriter) Flush() error {
+	return errors.New("flush failed")
+}
+
+func TestTraceCloseKeepsFailedFilesPart(t *testing.T) {
+	dir := t.TempDir()
+	e := endpoint{Interfaces: []captureInterface{
+		{Name: "eth0", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+		{Name: "lo", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+	}}
+	tr, err := openTrace(filepath.Join(dir, "trace"), storageConfig{Format: formatPcap, Snaplen: 65535}, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.writers[1] = failingWriter{}
+	finished, err := tr.Close()
+	if err == nil {
+		t.Fatal("close did not report the failed flush")
+	}
+	if len(finished) != 1 || finished[0] != tr.paths[0] {
+		t.Errorf("finished %v, want %s", finished, tr.paths[0])
+	}
+	if _, err := os.Stat(tr.paths[0]); err != nil {
+		t.Errorf("complete file was not finalized: %v", err)
+	}
+	if _, err := os.Stat(tr.paths[1]); !os.IsNotExist(err) {
+		t.Errorf("failed file was finalized: %v", err)
+	}
+	if _, err := os.Stat(tr.paths[1] + partSuffix); err != nil {
+		t.Errorf("failed file lost its part name: %v", err)
+	}
+}
+
+// partFiles lists the files under dir still carrying the part suffix
+func partFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	parts, err := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, _ := filepath.Glob(filepath.Join(dir, "*"+partSuffix))
+	return append(parts, top...)
+}
+
+func TestSegmentsKeepFinishedFilesOfFailedTrace(t *testing.T) {
+	storage := storageConfig{Dir: t.TempDir(), Layout: "{root}/{session}", Format: formatPcap, Snaplen: 65535}
+	e := endpoint{SessionID: "s", Interfaces: []captureInterface{
+		{Name: "eth0", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+		{Name: "lo", LinkType: layers.LinkTypeEthernet, Snaplen: 65535},
+	}}
+	s, err := openSegments(storage, e, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.trace.writers[1] = failingWriter{}
+	if err := s.Close(); err == nil {
+		t.Fatal("close did not report the failed flush")
+	}
+	want := filepath.Join(storage.Dir, "s-if0.pcap")
+	if _, _, closed := s.Stored(); len(closed) != 1 || closed[0] != want {
+		t.Errorf("closed %v, want %s", closed, want)
+	}
+}
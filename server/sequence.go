@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxOpenGaps bounds how many gaps are remembered for packets arriving late
+const maxOpenGaps = 64
+
+// sequenceStats follows the packet sequence numbers of a client to find
+// packets lost or delivered twice between client and collector. Sessions of
+// the same client instance carry it over, otherwise the first numbered packet
+// of a session sets where its sequence starts.
+type sequenceStats struct {
+	Last       uint64
+	Gaps       uint64
+	Missing    uint64
+	Late       uint64
+	Duplicates uint64
+	// Unmatched counts packets older than the gaps still remembered, they
+	// are kept as they may be late or duplicates
+	Unmatched uint64
+	open      []sequenceGap
+	// forgotten is the end of the newest gap dropped from open
+	forgotten uint64
+}
+
+// sequenceCheck is what the sequence number of a packet says about it
+type sequenceCheck struct {
+	// Missing packets went missing right before it
+	Missing uint64
+	// Late fills a gap found earlier
+	Late bool
+	// Duplicate was received already, it is not written to the trace
+	Duplicate bool
+	// Unmatched is older than the gaps still remembered
+	Unmatched bool
+}
+
+// cursor returns where s stands in the sequence, with open gaps of its own,
+// for another session to go on with. The counters start over so that totals
+// over sessions count each gap once.
+func (s sequenceStats) cursor() sequenceStats {
+	return sequenceStats{
+		Last:      s.Last,
+		open:      append([]sequenceGap(nil), s.open...),
+		forgotten: s.forgotten,
+	}
+}
+
+// sequenceGap is a range of sequence numbers not received yet
+type sequenceGap struct {
+	from, to uint64
+}
+
+// check tracks seq and tells what it says about its packet. Unnumbered
+// packets are never checked.
+func (s *sequenceStats) check(seq uint64) sequenceCheck {
+	var c sequenceCheck
+	switch {
+	case seq == 0:
+		return c
+	case s.Last == 0:
+	case seq <= s.Last:
+		switch {
+		case s.fill(seq):
+			s.Late++
+			s.Missing--
+			c.Late = true
+		case seq <= s.forgotten:
+			s.Unmatched++
+			c.Unmatched = true
+		default:
+			s.Duplicates++
+			c.Duplicate = true
+		}
+		return c
+	case seq > s.Last+1:
+		c.Missing = seq - s.Last - 1
+		s.Gaps++
+		s.Missing += c.Missing
+		s.open = append(s.open, sequenceGap{from: s.Last + 1, to: seq - 1})
+		if len(s.open) > maxOpenGaps {
+			s.forgotten = s.open[0].to
+			s.open = s.open[1:]
+		}
+	}
+	s.Last = seq
+	return c
+}
+
+// fill removes seq from the open gaps, it reports false when seq is in none of them
+func (s *sequenceStats) fill(seq uint64) bool {
+	for i, g := range s.open {
+		if seq < g.from || seq > g.to {
+			continue
+		}
+		switch {
+		case g.from == g.to:
+			s.open = append(s.open[:i:i], s.open[i+1:]...)
+		case seq == g.from:
+			s.open[i].from++
+		case seq == g.to:
+			s.open[i].to--
+		default:
+			rest := sequenceGap{from: seq + 1, to: g.to}
+			s.open[i].to = seq - 1
+			s.open = append(s.open[:i+1:i+1], append([]sequenceGap{rest}, s.open[i+1:]...)...)
+		}
+		return true
+	}
+	return false
+}
+
+// gapLog records the gaps and duplicates found in a trace in a sidecar file
+// next to it, the file is only created once there is something to record.
+// Runs of duplicates, as left by a replayed spool, are logged as one line.
+type gapLog struct {
+	path    string
+	file    *os.File
+	dupFrom uint64
+	dupTo   uint64
+}
+
+func newGapLog(tracePath string) *gapLog {
+	return &gapLog{path: tracePath + ".gaps"}
+}
+
+// missing logs the n packets missing before seq
+func (l *gapLog) missing(seq, n uint64) {
+	l.flushDuplicates()
+	l.write("missing %d packets, sequence %d to %d", n, seq-n, seq-1)
+}
+
+// late logs that seq arrived after packets following it, it is still written to the trace
+func (l *gapLog) late(seq uint64) {
+	l.flushDuplicates()
+	l.write("late packet, sequence %d", seq)
+}
+
+// unmatched logs that seq is older than the gaps still remembered, it is
+// written to the trace though it may be a duplicate
+func (l *gapLog) unmatched(seq uint64) {
+	l.flushDuplicates()
+	l.write("packet older than the gaps remembered, sequence %d, kept though it may be a duplicate", seq)
+}
+
+// duplicate logs that seq was received again and not written to the trace
+func (l *gapLog) duplicate(seq uint64) {
+	if l.dupTo != 0 && seq == l.dupTo+1 {
+		l.dupTo = seq
+		return
+	}
+	l.flushDuplicates()
+	l.dupFrom, l.dupTo = seq, seq
+}
+
+func (l *gapLog) flushDuplicates() {
+	if l.dupTo == 0 {
+		return
+	}
+	l.write("dropped %d duplicate packets, sequence %d to %d", l.dupTo-l.dupFrom+1, l.dupFrom, l.dupTo)
+	l.dupFrom, l.dupTo = 0, 0
+}
+
+func (l *gapLog) write(format string, args ...interface{}) {
+	if l.file == nil {
+		f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		l.file = f
+	}
+	fmt.Fprintf(l.file, "%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+func (l *gapLog) Close() error {
+	l.flushDuplicates()
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
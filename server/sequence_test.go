@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSequenceCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		seqs []uint64
+		want []sequenceCheck
+		end  sequenceStats
+	}{
+		{
+			name: "in order",
+			seqs: []uint64{1, 2, 3},
+			want: []sequenceCheck{{}, {}, {}},
+			end:  sequenceStats{Last: 3},
+		},
+		{
+			name: "first packet sets the start",
+			seqs: []uint64{40, 41},
+			want: []sequenceCheck{{}, {}},
+			end:  sequenceStats{Last: 41},
+		},
+		{
+			name: "unnumbered",
+			seqs: []uint64{0, 0},
+			want: []sequenceCheck{{}, {}},
+		},
+		{
+			name: "gap",
+			seqs: []uint64{1, 5},
+			want: []sequenceCheck{{}, {Missing: 3}},
+			end:  sequenceStats{Last: 5, Gaps: 1, Missing: 3},
+		},
+		{
+			name: "late fills the gap",
+			seqs: []uint64{1, 4, 2, 3},
+			want: []sequenceCheck{{}, {Missing: 2}, {Late: true}, {Late: true}},
+			end:  sequenceStats{Last: 4, Gaps: 1, Missing: 0, Late: 2},
+		},
+		{
+			name: "duplicate",
+			seqs: []uint64{1, 2, 2, 1},
+			want: []sequenceCheck{{}, {}, {Duplicate: true}, {Duplicate: true}},
+			end:  sequenceStats{Last: 2, Duplicates: 2},
+		},
+		{
+			name: "late packet received twice",
+			seqs: []uint64{1, 3, 2, 2},
+			want: []sequenceCheck{{}, {Missing: 1}, {Late: true}, {Duplicate: true}},
+			end:  sequenceStats{Last: 3, Gaps: 1, Late: 1, Duplicates: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s sequenceStats
+			for i, seq := range tt.seqs {
+				if got := s.check(seq); got != tt.want[i] {
+					t.Errorf("check(%d) = %+v, want %+v", seq, got, tt.want[i])
+				}
+			}
+			s.open, s.forgotten = nil, 0
+			if !reflect.DeepEqual(s, tt.end) {
+				t.Errorf("stats = %+v, want %+v", s, tt.end)
+			}
+		})
+	}
+}
+
+func TestSequenceFillSplitsGaps(t *testing.T) {
+	var s sequenceStats
+	s.check(1)
+	s.check(10)
+	for _, seq := range []uint64{5, 2, 9} {
+		if !s.check(seq).Late {
+			t.Fatalf("%d not late", seq)
+		}
+	}
+	want := []sequenceGap{{3, 4}, {6, 8}}
+	if fmt.Sprint(s.open) != fmt.Sprint(want) {
+		t.Errorf("open = %v, want %v", s.open, want)
+	}
+}
+
+func TestSequenceForgottenGaps(t *testing.T) {
+	var s sequenceStats
+	seq := uint64(1)
+	s.check(seq)
+	// every other packet goes missing until the first gaps are forgotten
+	for i := 0; i < maxOpenGaps+2; i++ {
+		seq += 2
+		s.check(seq)
+	}
+	if len(s.open) != maxOpenGaps {
+		t.Fatalf("%d open gaps, want %d", len(s.open), maxOpenGaps)
+	}
+	// 2 and 4 were in the forgotten gaps, they are kept rather than dropped
+	for _, old := range []uint64{2, 4} {
+		if c := s.check(old); !c.Unmatched || c.Duplicate {
+			t.Errorf("check(%d) = %+v, want unmatched", old, c)
+		}
+	}
+	if c := s.check(6); !c.Late {
+		t.Errorf("check(6) = %+v, want late", c)
+	}
+	if c := s.check(5); !c.Duplicate {
+		t.Errorf("check(5) = %+v, want duplicate", c)
+	}
+	if s.Unmatched != 2 {
+		t.Errorf("Unmatched = %d, want 2", s.Unmatched)
+	}
+}
+
+func TestRegistryCarriesSequenceAcrossSessions(t *testing.T) {
+	r := newRegistry(idleAfter, expireAfter)
+	r.Register(endpoint{SessionID: "a", Hostname: "h", Instance: "i1"})
+	r.CheckSequence("a", 1)
+	r.CheckSequence("a", 2)
+	// 3 went missing in the first session
+	r.CheckSequence("a", 4)
+
+	tests := []struct {
+		name string
+		e    endpoint
+		seq  uint64
+		want sequenceCheck
+	}{
+		// 5 and 6 went missing while the client reconnected
+		{"same instance", endpoint{SessionID: "b", Hostname: "h", Instance: "i1"}, 7, sequenceCheck{Missing: 2}},
+		{"new instance", endpoint{SessionID: "c", Hostname: "h", Instance: "i2"}, 6, sequenceCheck{}},
+		{"no instance", endpoint{SessionID: "d", Hostname: "h"}, 6, sequenceCheck{}},
+		{"other client", endpoint{SessionID: "e", Hostname: "other", Instance: "i1"}, 6, sequenceCheck{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Register(tt.e)
+			if got := r.CheckSequence(tt.e.SessionID, tt.seq); got != tt.want {
+				t.Errorf("CheckSequence = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	// the first session is left as it was
+	if e, _ := r.Lookup("a"); e.Sequence.Last != 4 || e.Sequence.Gaps != 1 {
+		t.Errorf("first session Last = %d Gaps = %d, want 4 and 1", e.Sequence.Last, e.Sequence.Gaps)
+	}
+	// the gap of the first session is still open, its counters are not
+	// carried over
+	if got := r.CheckSequence("b", 3); !got.Late {
+		t.Errorf("CheckSequence of the first session's gap = %+v, want late", got)
+	}
+	// summed over both sessions 5 and 6 are still missing
+	if e, _ := r.Lookup("b"); e.Sequence.Gaps != 1 || e.Sequence.Missing != 1 || e.Sequence.Late != 1 {
+		t.Errorf("second session counted %+v, want its own gap only", e.Sequence)
+	}
+}
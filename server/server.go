@@ -1,166 +1,476 @@
 package main
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
-	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
-	"github.com/google/gopacket"
+	v1 "github.com/alwashali/gRPC-Remote-Traffic-Capture/service/v1"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
-	"github.com/google/gopacket/pcapgo"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	service.UnimplementedRemoteCaputreServer
+	endpoints *registry
+	control   *controlHub
+	// enroll is nil unless agents must enroll
+	enroll *enrollment
+	cfgMu  sync.RWMutex
+	cfg    *config
 }
 
+// version is reported to clients in GetReady, release builds set it with
+// -ldflags "-X main.version=1.2.0"
+var version = "dev"
+
 var (
-	snapshotLen uint32 = 1024
+	// snapshotLen is written to the traces of clients that don't describe
+	// their capture, it is what clients capture by default
+	snapshotLen uint32 = 65535
 	err         error
 	timeout     time.Duration = -1 * time.Second
 	handle      *pcap.Handle
+
+	// streams without packets for idleAfter are idle, endpoints without a
+	// stream for expireAfter must call GetReady again
+	idleAfter   = time.Minute
+	expireAfter = time.Hour
 )
 
-type endpoint struct {
-	Hostname      string
-	IPAddress     string
-	Interface     string
-	TraceFileName string
-	Packetcount   int
-	StreamingNow  bool
+// newSessionID returns a random identifier for a GetReady registration
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
-var endpoints []endpoint
+func (s *Server) GetReady(ctx context.Context, info *service.EndpointInfo) (*service.ReadyReply, error) {
+	// registered clients describe each new capture under their session
+	if _, err := sessionID(ctx); err == nil {
+		session, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		e, ok := s.endpoints.Lookup(session)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+		}
+		// policies may have changed on reload since the session registered
+		if s.config().policy(e.Hostname, e.address()).Deny {
+			fmt.Printf("%s (%s) denied by policy\n", e.Hostname, e.address())
+			return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to register", e.Hostname)
+		}
+		if !s.endpoints.SetCapture(session, describedInterfaces(info)) {
+			return nil, status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+		}
+		e, _ = s.endpoints.Lookup(session)
+		return s.readyReply(e, info), nil
+	}
 
-func (s *Server) GetReady(ctx context.Context, info *service.EndpointInfo) (*service.Empty, error) {
 	fmt.Printf("%s is connecting ... \n", info.IPaddress)
-	_, Found := s.GetEndpointInfo(info.IPaddress)
-	if !Found {
-		e := endpoint{
-			Hostname:  info.Hostname,
-			IPAddress: info.IPaddress,
-			TraceFileName: info.Hostname +
-				"-" +
-				"(" + info.IPaddress + ") ",
-			Packetcount: 0,
+	peerAddress := peerHost(ctx)
+	address := peerAddress
+	if address == "" {
+		address = info.IPaddress
+	}
+	// with mutual TLS the endpoint is who its certificate says
+	hostname := info.Hostname
+	identity, commonName := peerIdentity(ctx)
+	if commonName != "" {
+		hostname = commonName
+	}
+	if s.config().policy(hostname, address).Deny {
+		fmt.Printf("%s (%s) denied by policy\n", hostname, address)
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to register", hostname)
+	}
+	sessionID, err := newSessionID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can not create session: %v", err)
+	}
+	var agent, credential string
+	if s.enroll != nil {
+		if agent, credential, err = s.enrollAgent(ctx, info, hostname); err != nil {
+			return nil, err
 		}
-		//Append new endpoint connection to endpoints slice
-		endpoints = append(endpoints, e)
-		fmt.Printf("%s added\n", info.Hostname)
-
 	}
-	return &service.Empty{}, nil
+	e := endpoint{
+		SessionID:   sessionID,
+		Hostname:    hostname,
+		IPAddress:   info.IPaddress,
+		Peer:        peerAddress,
+		OS:          info.OS,
+		Identity:    identity,
+		Agent:       agent,
+		Instance:    info.Instance,
+		Interfaces:  describedInterfaces(info),
+		Packetcount: 0,
+	}
+	s.endpoints.Register(e)
+	fmt.Printf("%s added\n", hostname)
+
+	reply := s.readyReply(e, info)
+	reply.Credential = credential
+	return reply, nil
 }
 
-func (s *Server) GetEndpointInfo(addr string) (int, bool) {
+// readyReply describes the session of e to the client that sent info. Typed
+// capture info and batching are accepted whenever the client asks for them,
+// older clients keep sending single packets with JSON metadata, never
+// compress and ignore the limits.
+func (s *Server) readyReply(e endpoint, info *service.EndpointInfo) *service.ReadyReply {
+	c := s.config()
+	rate, filters := c.limitsFor(e)
+	return &service.ReadyReply{
+		CaptureInfoFormat: info.CaptureInfoFormat,
+		Batching:          info.Batching,
+		SessionID:         e.SessionID,
+		Compressors:       c.compressorsFor(e),
+		ServerVersion:     version,
+		Snaplen:           c.storageFor(e).Snaplen,
+		MaxRate:           uint64(rate),
+		RequiredFilters:   filters,
+	}
+}
 
-	for i, e := range endpoints {
-		if e.IPAddress == addr {
-			return i, true
+// describedInterfaces returns the interfaces of the capture info describes,
+// older clients only name the interface they capture on
+func describedInterfaces(info *service.EndpointInfo) []captureInterface {
+	if len(info.Interfaces) == 0 {
+		if info.Interface == "" && info.Snaplen == 0 {
+			return nil
+		}
+		return []captureInterface{{
+			Name:     info.Interface,
+			LinkType: layers.LinkType(info.LinkType),
+			Snaplen:  info.Snaplen,
+			Filter:   info.Filter,
+		}}
+	}
+	interfaces := make([]captureInterface, len(info.Interfaces))
+	for i, intf := range info.Interfaces {
+		interfaces[i] = captureInterface{
+			Name:     intf.Name,
+			LinkType: layers.LinkType(intf.LinkType),
+			Snaplen:  intf.Snaplen,
+			Filter:   intf.Filter,
 		}
+	}
+	return interfaces
+}
 
+func (s *Server) GetEndpointInfo(sessionID string) (endpoint, bool) {
+	return s.endpoints.Lookup(sessionID)
+}
+
+// peerHost returns the IP address ctx came from, empty for unix sockets
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil || net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
+}
+
+// sessionID returns the session ID a stream carries in its metadata
+func sessionID(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(service.SessionMetadataKey)
+	if len(ids) == 0 || ids[0] == "" {
+		return "", status.Error(codes.Unauthenticated, "missing session id, call GetReady first")
 	}
-	return 0, false
+	return ids[0], nil
 }
 
 func (s *Server) Capture(srv service.RemoteCaputre_CaptureServer) error {
-	ctx := srv.Context()
-	p, _ := peer.FromContext(ctx)
-	ipaddress := strings.Split(p.Addr.String(), ":")[0]
-	fmt.Println("capture started ", ipaddress)
-	endpoint, Found := s.GetEndpointInfo(ipaddress)
-	if !Found {
-		log.Panic()
-		//handle properly
+	summary, err := s.receive(srv.Context(), func() ([]*service.Packet, error) {
+		pkt, err := srv.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return []*service.Packet{pkt}, nil
+	})
+	if err != nil {
+		return err
 	}
-	file, err := os.OpenFile(
-		endpoints[endpoint].TraceFileName+time.Now().Format(time.RFC850)+".pcap",
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644,
-	)
+	return srv.SendAndClose(summary)
+}
+
+// CaptureBatch is Capture for clients that negotiated batching, every
+// PacketBatch frame is unbatched and written packet by packet.
+func (s *Server) CaptureBatch(srv service.RemoteCaputre_CaptureBatchServer) error {
+	var sequence uint64
+	summary, err := s.receive(srv.Context(), func() ([]*service.Packet, error) {
+		batch, err := srv.Recv()
+		if err != nil {
+			return nil, err
+		}
+		sequence = checkBatchSequence(sequence, batch.Sequence)
+		return batch.Packets, nil
+	})
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+	return srv.SendAndClose(summary)
+}
+
+// checkBatchSequence returns the sequence number of the batch following last,
+// logging batches that don't follow it
+func checkBatchSequence(last, sequence uint64) uint64 {
+	if sequence != last+1 {
+		fmt.Printf("batch sequence jumped from %d to %d\n", last, sequence)
 	}
-	defer file.Close()
+	return sequence
+}
 
+// receive writes the packets returned by next to a new trace file until the
+// stream ends and sums up what it stored
+func (s *Server) receive(ctx context.Context, next func() ([]*service.Packet, error)) (*service.CaptureSummary, error) {
+	session, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, Found := s.endpoints.StreamStarted(session)
+	if !Found {
+		return nil, status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+	}
+	defer s.endpoints.StreamEnded(session)
+	if p, ok := peer.FromContext(ctx); ok {
+		fmt.Println("capture started ", endpoint.Hostname, p.Addr)
+	}
 	//go packet writer
-	w := pcapgo.NewWriter(file)
-	w.WriteFileHeader(snapshotLen, layers.LinkTypeEthernet)
+	storage := s.config().storageFor(endpoint)
+	if err := checkDiskSpace(storage); err != nil {
+		fmt.Printf("stream from %s rejected: %v\n", endpoint.Hostname, err)
+		return nil, err
+	}
+	w, err := openSegments(storage, endpoint, time.Now())
+	if err != nil {
+		fmt.Println(err)
+		return nil, status.Errorf(codes.Internal, "can not write trace: %v", err)
+	}
 
 	StreamEnd := make(chan bool)
-	endpoints[endpoint].StreamingNow = true
+	var streamErr error
+	var gaps, missingTotal uint64
 	go func() {
 		for {
 
 			// receive data from stream
-			pkt, err := srv.Recv()
+			packets, err := next()
 
 			if err != nil {
 				//log.Fatalf("Failed to receive the packet : %v", err)
 				StreamEnd <- true
 				break
 			}
-
-			// empty CaptureInfo struct
-			metadata := gopacket.PacketMetadata{}
-			err = json.Unmarshal(pkt.Seralizedcapturreinfo, &metadata)
-			if err != nil {
-				fmt.Printf("Error unmarshal the packet %s \n", err)
-				continue
+			// revoked agents are cut off at their next frame
+			if endpoint.Agent != "" && !s.enroll.Enrolled(endpoint.Agent) {
+				streamErr = status.Errorf(codes.PermissionDenied, "agent %s was revoked", endpoint.Agent)
+				StreamEnd <- true
+				break
 			}
 
-			err = w.WritePacket(metadata.CaptureInfo, pkt.Data)
-
-			if err != nil {
-				fmt.Println(err)
+			written := 0
+			for _, pkt := range packets {
+				if err := w.Ready(time.Now()); err != nil {
+					fmt.Println(err)
+					continue
+				}
+				check := s.endpoints.CheckSequence(session, pkt.Sequence)
+				if check.Missing > 0 {
+					fmt.Printf("%d packets from %s went missing before %d\n", check.Missing, endpoint.Hostname, pkt.Sequence)
+					w.Missing(pkt.Sequence, check.Missing)
+					gaps++
+					missingTotal += check.Missing
+				}
+				if check.Late {
+					w.Late(pkt.Sequence)
+				}
+				if check.Unmatched {
+					w.Unmatched(pkt.Sequence)
+				}
+				if check.Duplicate {
+					w.Duplicate(pkt.Sequence)
+					continue
+				}
+
+				captureInfo, err := service.PacketCaptureInfo(pkt)
+				if err != nil {
+					fmt.Printf("Error unmarshal the packet %s \n", err)
+					continue
+				}
+				data := pkt.Data
+				if uint32(len(data)) > storage.Snaplen {
+					data = data[:storage.Snaplen]
+					captureInfo.CaptureLength = len(data)
+				}
+
+				if err := w.WritePacket(captureInfo, data); err != nil {
+					fmt.Println(err)
+					continue
+				}
+				written++
 			}
 
-			endpoints[endpoint].Packetcount++
-
-			fmt.Printf("Received...\nPacketCount: %d ", endpoints[endpoint].Packetcount)
+			fmt.Printf("Received...\nPacketCount: %d ", s.endpoints.AddPackets(session, written))
 
 		}
 
 	}()
 
-	<-StreamEnd
-	log.Printf("stream ended from %s \n", endpoints[endpoint].IPAddress)
-	endpoints[endpoint].StreamingNow = false
-	return nil
+	// segments are flushed once a second and closed on time even while no
+	// packets arrive
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for ended := false; !ended; {
+		select {
+		case <-StreamEnd:
+			ended = true
+		case now := <-ticker.C:
+			if err := w.Tick(now); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	log.Printf("stream ended from %s \n", endpoint.IPAddress)
+	if err := w.Close(); err != nil {
+		fmt.Println(err)
+	}
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	packets, bytes, files := w.Stored()
+	return &service.CaptureSummary{
+		Packets: packets,
+		Bytes:   bytes,
+		Files:   files,
+		Gaps:    gaps,
+		Missing: missingTotal,
+	}, nil
+}
 
+// ReportStats records the libpcap counters of a capture, so that traces
+// missing packets dropped on the endpoint can be told apart
+func (s *Server) ReportStats(ctx context.Context, stats *service.CaptureStats) (*service.Empty, error) {
+	session, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dropped, ok := s.endpoints.SetCaptureStats(session, stats.Interface, captureStats{
+		Received:  stats.PacketsReceived,
+		Dropped:   stats.PacketsDropped,
+		IfDropped: stats.PacketsIfDropped,
+		Captured:  stats.Captured,
+		Updated:   time.Now(),
+	})
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+	}
+	if dropped > 0 {
+		endpoint, _ := s.endpoints.Lookup(session)
+		fmt.Printf("%s dropped %d packets on %s, its trace is incomplete\n", endpoint.Hostname, dropped, stats.Interface)
+	}
+	return &service.Empty{}, nil
 }
 
+var (
+	traceFormat = flag.String("format", formatPcap, "Trace file format: pcap or pcapng")
+	tlsCert     = flag.String("tlscert", "", "Serve TLS with this certificate file, requires -tlskey")
+	tlsKey      = flag.String("tlskey", "", "Key file of -tlscert")
+	tlsCA       = flag.String("tlsca", "", "Require client certificates issued by the CA in this file (mutual TLS)")
+	authFile    = flag.String("auth", "", "Require agents to enroll with a token, keeping tokens and agent credentials in this file")
+	grpcAddr    = flag.String("listen", "0.0.0.0:9000", "Listen address of the collector: host:port, [IPv6]:port or unix:/path/to/socket")
+	httpAddr    = flag.String("http", ":8080", "Listen address of the exceptions list server, empty disables it")
+	adminAddr   = flag.String("admin", "127.0.0.1:8081", "Listen address of the admin API, keep it on loopback or a unix socket")
+)
+
 func main() {
-	lis, err := net.Listen("tcp", "0.0.0.0:9000")
+	flag.Parse()
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+
+	lis, err := listen(cfg.Listen.GRPC)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
+	endpoints := newRegistry(cfg.Sessions.IdleAfter, cfg.Sessions.ExpireAfter)
+	go endpoints.Run(10 * time.Second)
+	s := &Server{endpoints: endpoints, control: newControlHub(), cfg: cfg}
+	if cfg.Auth.File != "" {
+		s.enroll, err = openEnrollment(cfg.Auth.File)
+		if err != nil {
+			log.Fatalf("failed to load enrollment: %v", err)
+		}
+	}
+	go s.reloadOnHangup()
+	go s.janitor()
+
 	// Serve the exception list over http
-	go func() {
+	if cfg.Listen.HTTP != "" {
+		httpLis, err := listen(cfg.Listen.HTTP)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		go func() {
+
+			// the directory is looked up on every request, it may change on reload
+			http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				http.FileServer(http.Dir(s.config().Public)).ServeHTTP(w, r)
+			})
 
-		fs := http.FileServer(http.Dir("./public"))
-		http.Handle("/", fs)
+			log.Printf("Listening on %s...", cfg.Listen.HTTP)
+			err := http.Serve(httpLis, nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
 
-		log.Println("Listening on :8080...")
-		err := http.ListenAndServe(":8080", nil)
+	// Serve the operator API, on loopback only by default
+	adminLis, err := listen(cfg.Listen.Admin)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		log.Printf("Admin API listening on %s...", cfg.Listen.Admin)
+		err := http.Serve(adminLis, s.adminHandler())
 		if err != nil {
 			log.Fatal(err)
 		}
 	}()
 
-	grpcserver := grpc.NewServer()
-	service.RegisterRemoteCaputreServer(grpcserver, &Server{})
-	fmt.Println("Server started. ")
+	opts, err := serverOptions(cfg.TLS)
+	if err != nil {
+		log.Fatalf("failed to set up TLS: %v", err)
+	}
+	grpcserver := grpc.NewServer(opts...)
+	// the legacy service and the v1 API are served side by side
+	service.RegisterRemoteCaputreServer(grpcserver, s)
+	v1.RegisterRemoteCaptureServer(grpcserver, &v1Server{s: s})
+	fmt.Printf("Server started on %s\n", cfg.Listen.GRPC)
 	if err := grpcserver.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// withCertificate is ctx of a client that presented a verified certificate for commonName
+func withCertificate(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000},
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestGetReadyDenyPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		commonName string
+		hostname   string
+		deny       bool
+	}{
+		{name: "hostname", hostname: "lab-1", deny: true},
+		{name: "other hostname", hostname: "web01"},
+		{name: "certificate", commonName: "lab-1", hostname: "web01", deny: true},
+		{name: "certificate of another host", commonName: "web01", hostname: "lab-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Endpoints = []endpointPolicy{{Hostname: "lab-*", Deny: true}}
+			s := &Server{endpoints: newRegistry(idleAfter, expireAfter), control: newControlHub(), cfg: cfg}
+			ctx := context.Background()
+			if tt.commonName != "" {
+				ctx = withCertificate(ctx, tt.commonName)
+			}
+			_, err := s.GetReady(ctx, &service.EndpointInfo{Hostname: tt.hostname, IPaddress: "192.0.2.1"})
+			if denied := status.Code(err) == codes.PermissionDenied; denied != tt.deny || !denied && err != nil {
+				t.Errorf("GetReady = %v, want denied %v", err, tt.deny)
+			}
+		})
+	}
+}
+
+func TestGetReadyDenyPolicyAfterReload(t *testing.T) {
+	s := &Server{endpoints: newRegistry(idleAfter, expireAfter), control: newControlHub(), cfg: defaultConfig()}
+	info := &service.EndpointInfo{Hostname: "lab-1", IPaddress: "192.0.2.1"}
+	reply, err := s.GetReady(context.Background(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(service.SessionMetadataKey, reply.SessionID))
+	if _, err := s.GetReady(ctx, info); err != nil {
+		t.Fatalf("describing a capture: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.Endpoints = []endpointPolicy{{Hostname: "lab-*", Deny: true}}
+	s.cfgMu.Lock()
+	s.cfg = cfg
+	s.cfgMu.Unlock()
+	if _, err := s.GetReady(ctx, info); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("describing a capture after the host was denied: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// serverOptions returns the grpc options serving TLS as c says, none when
+// serving plaintext. With a CA clients must present a certificate issued by it.
+func serverOptions(c tlsConfig) ([]grpc.ServerOption, error) {
+	if c.Cert == "" && c.Key == "" {
+		if c.CA != "" {
+			return nil, fmt.Errorf("-tlsca requires -tlscert and -tlskey")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.CA != "" {
+		pool, err := service.LoadCertPool(c.CA)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(config))}, nil
+}
+
+// peerIdentity returns the subject and common name of the verified client
+// certificate of ctx, both are empty without mutual TLS
+func peerIdentity(ctx context.Context) (subject string, commonName string) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", ""
+	}
+	cert := info.State.VerifiedChains[0][0]
+	return cert.Subject.String(), cert.Subject.CommonName
+}
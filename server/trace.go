@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const (
+	formatPcap   = "pcap"
+	formatPcapng = "pcapng"
+)
+
+// traceWriter writes packets to a trace file, Flush must be called before the
+// file is closed
+type traceWriter interface {
+	WritePacket(ci gopacket.CaptureInfo, data []byte) error
+	Flush() error
+}
+
+// pcapWriter writes classic pcap, which is not buffered
+type pcapWriter struct {
+	*pcapgo.Writer
+}
+
+func (pcapWriter) Flush() error {
+	return nil
+}
+
+// partSuffix marks trace files still being written, they are renamed without
+// it once closed so that only complete traces carry their final name
+const partSuffix = ".part"
+
+// trace is where the packets of one stream are written. pcapng traces hold
+// all interfaces of the capture, classic pcap can only hold one link type so
+// every interface gets a file of its own.
+type trace struct {
+	// path of the first file, the one the gap log is kept next to
+	path string
+	// final names of the files
+	paths       []string
+	files       []*os.File
+	compressors []compressor
+	writers     []traceWriter
+	// codec compresses the files, if set
+	codec *traceCodec
+}
+
+// openTrace creates the trace files for the capture of e, named base plus the
+// extension of the storage format, and writes their headers
+func openTrace(base string, storage storageConfig, e endpoint) (*trace, error) {
+	interfaces := make([]captureInterface, len(e.Interfaces))
+	copy(interfaces, e.Interfaces)
+	if len(interfaces) == 0 {
+		interfaces = append(interfaces, captureInterface{})
+	}
+	for i := range interfaces {
+		// clients that don't describe their capture are assumed to send Ethernet
+		if interfaces[i].Snaplen == 0 {
+			interfaces[i].LinkType, interfaces[i].Snaplen = layers.LinkTypeEthernet, storage.Snaplen
+		}
+		// packets are truncated to the storage snaplen
+		if interfaces[i].Snaplen > storage.Snaplen {
+			interfaces[i].Snaplen = storage.Snaplen
+		}
+	}
+
+	t := &trace{}
+	if codec, ok := traceCodecs[storage.Compression]; ok {
+		t.codec = &codec
+	}
+	switch storage.Format {
+	case formatPcap:
+		for i, intf := range interfaces {
+			path := base + ".pcap"
+			if len(interfaces) > 1 {
+				path = fmt.Sprintf("%s-if%d.pcap", base, i)
+			}
+			f, err := t.create(path)
+			if err != nil {
+				return nil, err
+			}
+			w := pcapgo.NewWriter(f)
+			if err := w.WriteFileHeader(intf.Snaplen, intf.LinkType); err != nil {
+				t.discard()
+				return nil, err
+			}
+			t.writers = append(t.writers, pcapWriter{w})
+		}
+	case formatPcapng:
+		f, err := t.create(base + ".pcapng")
+		if err != nil {
+			return nil, err
+		}
+		w, err := newNgWriter(f, e, interfaces)
+		if err != nil {
+			t.discard()
+			return nil, err
+		}
+		t.writers = append(t.writers, w)
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", storage.Format)
+	}
+	return t, nil
+}
+
+// create opens path, with the extension of the codec, under its part name.
+// Neither may exist already.
+func (t *trace) create(path string) (io.Writer, error) {
+	if t.codec != nil {
+		path += t.codec.ext
+	}
+	if _, err := os.Lstat(path); err == nil {
+		t.discard()
+		return nil, fmt.Errorf("trace %s already exists", path)
+	}
+	// a second header appended to an existing trace would corrupt it
+	f, err := os.OpenFile(path+partSuffix, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		t.discard()
+		return nil, err
+	}
+	if t.path == "" {
+		t.path = path
+	}
+	t.paths = append(t.paths, path)
+	t.files = append(t.files, f)
+	if t.codec == nil {
+		return f, nil
+	}
+	c := t.codec.newWriter(f)
+	t.compressors = append(t.compressors, c)
+	return c, nil
+}
+
+// newNgWriter writes a section describing the endpoint e, and an interface
+// description block for each of its interfaces
+func newNgWriter(w io.Writer, e endpoint, interfaces []captureInterface) (*pcapgo.NgWriter, error) {
+	section := pcapgo.NgWriterOptions{
+		SectionInfo: pcapgo.NgSectionInfo{
+			OS:          e.OS,
+			Application: "gRPC-Remote-Traffic-Capture",
+			Comment:     fmt.Sprintf("captured on %s (%s)", e.Hostname, e.IPAddress),
+		},
+	}
+	var ng *pcapgo.NgWriter
+	for i, intf := range interfaces {
+		desc := pcapgo.NgInterface{
+			Name:                intf.Name,
+			Description:         fmt.Sprintf("%s on %s", intf.Name, e.Hostname),
+			Filter:              intf.Filter,
+			OS:                  e.OS,
+			LinkType:            intf.LinkType,
+			SnapLength:          intf.Snaplen,
+			TimestampResolution: 9,
+		}
+		var err error
+		if i == 0 {
+			ng, err = pcapgo.NewNgWriterInterface(w, desc, section)
+		} else {
+			_, err = ng.AddInterface(desc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ng, nil
+}
+
+// WritePacket writes data to the file of the interface it was captured on
+func (t *trace) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	if len(t.writers) == 1 {
+		return t.writers[0].WritePacket(ci, data)
+	}
+	if ci.InterfaceIndex < 0 || ci.InterfaceIndex >= len(t.writers) {
+		return fmt.Errorf("packet from unknown interface %d", ci.InterfaceIndex)
+	}
+	return t.writers[ci.InterfaceIndex].WritePacket(ci, data)
+}
+
+func (t *trace) Flush() error {
+	for _, w := range t.writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, c := range t.compressors {
+		if err := c.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the trace files and gives them their final names,
+// which it returns. Files that could not be written completely keep their
+// part name.
+func (t *trace) Close() ([]string, error) {
+	var finished []string
+	var err error
+	failed := make([]bool, len(t.files))
+	fail := func(i int, ferr error) {
+		if ferr == nil {
+			return
+		}
+		failed[i] = true
+		if err == nil {
+			err = ferr
+		}
+	}
+	// writers and compressors are in the order of the files they write to
+	for i, w := range t.writers {
+		fail(i, w.Flush())
+	}
+	for i, c := range t.compressors {
+		fail(i, c.Close())
+	}
+	for i, f := range t.files {
+		fail(i, f.Close())
+		if !failed[i] {
+			fail(i, os.Rename(f.Name(), t.paths[i]))
+		}
+		if !failed[i] {
+			finished = append(finished, t.paths[i])
+		}
+	}
+	return finished, err
+}
+
+// discard closes and removes the files of a trace that could not be opened
+func (t *trace) discard() {
+	for _, c := range t.compressors {
+		c.Close()
+	}
+	for _, f := range t.files {
+		f.Close()
+		os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	v1 "github.com/alwashali/gRPC-Remote-Traffic-Capture/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// v1Server serves the v1 API on top of the handlers of the legacy service.
+// Both share the registry, sessions and metadata keys, so agents can move to
+// v1 one at a time.
+type v1Server struct {
+	v1.UnimplementedRemoteCaptureServer
+	s *Server
+}
+
+// v1Capabilities are the capabilities of the v1 API the server supports
+var v1Capabilities = map[v1.Capability]bool{
+	v1.Capability_CAPABILITY_SEQUENCE_NUMBERS: true,
+	v1.Capability_CAPABILITY_CONTROL:          true,
+	v1.Capability_CAPABILITY_STATS:            true,
+	v1.Capability_CAPABILITY_MULTI_INTERFACE:  true,
+	v1.Capability_CAPABILITY_COMPRESSION:      true,
+}
+
+// capabilityName is how the admin API lists a capability, such as control
+func capabilityName(c v1.Capability) string {
+	return strings.ToLower(strings.TrimPrefix(c.String(), "CAPABILITY_"))
+}
+
+// agreed returns the capabilities the session of ctx agreed on in Register,
+// calls of sessions registered with the legacy service agreed on none
+func (v *v1Server) agreed(ctx context.Context) (map[v1.Capability]bool, error) {
+	session, err := v.s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := v.s.endpoints.Lookup(session)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown or expired session %s", session)
+	}
+	agreed := make(map[v1.Capability]bool)
+	for c := range v1Capabilities {
+		for _, name := range e.Capabilities {
+			if name == capabilityName(c) {
+				agreed[c] = true
+			}
+		}
+	}
+	return agreed, nil
+}
+
+// require fails unless the session of ctx agreed on c
+func (v *v1Server) require(ctx context.Context, c v1.Capability) error {
+	agreed, err := v.agreed(ctx)
+	if err != nil {
+		return err
+	}
+	if !agreed[c] {
+		return notAgreed(c)
+	}
+	return nil
+}
+
+func notAgreed(c v1.Capability) error {
+	return status.Errorf(codes.FailedPrecondition, "%s was not agreed on in Register", capabilityName(c))
+}
+
+// Register is GetReady with a capability handshake, the client gets the
+// lower of both protocol versions and the capabilities both support
+func (v *v1Server) Register(ctx context.Context, req *v1.RegisterRequest) (*v1.RegisterResponse, error) {
+	if req.GetProtocolVersion() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "protocol_version must be 1 or higher")
+	}
+	version := req.GetProtocolVersion()
+	if version > v1.ProtocolVersion {
+		version = v1.ProtocolVersion
+	}
+	var agreed []v1.Capability
+	var names []string
+	compression := false
+	for _, c := range req.GetCapabilities() {
+		if v1Capabilities[c] {
+			agreed = append(agreed, c)
+			names = append(names, capabilityName(c))
+			compression = compression || c == v1.Capability_CAPABILITY_COMPRESSION
+		}
+	}
+
+	e := req.GetEndpoint()
+	info := &service.EndpointInfo{
+		Hostname:          e.GetHostname(),
+		IPaddress:         e.GetIpAddress(),
+		OS:                e.GetOs(),
+		CaptureInfoFormat: service.CaptureInfoFormat_TYPED,
+		Batching:          true,
+		EnrollmentToken:   e.GetEnrollmentToken(),
+		Instance:          e.GetInstance(),
+	}
+	for _, intf := range e.GetInterfaces() {
+		info.Interfaces = append(info.Interfaces, &service.CaptureInterface{
+			Name:     intf.GetName(),
+			LinkType: intf.GetLinkType(),
+			Snaplen:  intf.GetSnaplen(),
+			Filter:   intf.GetFilter(),
+		})
+	}
+	reply, err := v.s.GetReady(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	v.s.endpoints.SetProtocol(reply.GetSessionID(), version, names)
+
+	session := &v1.Session{
+		Id:              reply.GetSessionID(),
+		ServerVersion:   reply.GetServerVersion(),
+		Snaplen:         reply.GetSnaplen(),
+		MaxRate:         reply.GetMaxRate(),
+		RequiredFilters: reply.GetRequiredFilters(),
+		Credential:      reply.GetCredential(),
+	}
+	if compression {
+		session.Compressors = reply.GetCompressors()
+	}
+	return &v1.RegisterResponse{ProtocolVersion: version, Capabilities: agreed, Session: session}, nil
+}
+
+// StreamPackets is CaptureBatch. Sequence numbers and interfaces other than
+// the first need their capability.
+func (v *v1Server) StreamPackets(srv v1.RemoteCapture_StreamPacketsServer) error {
+	agreed, err := v.agreed(srv.Context())
+	if err != nil {
+		return err
+	}
+	var sequence uint64
+	// rejected ends the stream at the first batch using a capability that
+	// was not agreed on, receive takes any error for the end of the stream
+	var rejected error
+	reject := func(c v1.Capability) ([]*service.Packet, error) {
+		rejected = notAgreed(c)
+		return nil, rejected
+	}
+	summary, err := v.s.receive(srv.Context(), func() ([]*service.Packet, error) {
+		batch, err := srv.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if batch.GetSequence() != 0 && !agreed[v1.Capability_CAPABILITY_SEQUENCE_NUMBERS] {
+			return reject(v1.Capability_CAPABILITY_SEQUENCE_NUMBERS)
+		}
+		sequence = checkBatchSequence(sequence, batch.GetSequence())
+		packets := make([]*service.Packet, len(batch.GetPackets()))
+		for i, pkt := range batch.GetPackets() {
+			info := pkt.GetInfo()
+			switch {
+			case pkt.GetSequence() != 0 && !agreed[v1.Capability_CAPABILITY_SEQUENCE_NUMBERS]:
+				return reject(v1.Capability_CAPABILITY_SEQUENCE_NUMBERS)
+			case info.GetInterfaceIndex() != 0 && !agreed[v1.Capability_CAPABILITY_MULTI_INTERFACE]:
+				return reject(v1.Capability_CAPABILITY_MULTI_INTERFACE)
+			}
+			packets[i] = &service.Packet{
+				Data:     pkt.GetData(),
+				Sequence: pkt.GetSequence(),
+				Info: &service.CaptureInfo{
+					TimestampSeconds: info.GetTimestampSeconds(),
+					TimestampNanos:   info.GetTimestampNanos(),
+					CaptureLength:    info.GetCaptureLength(),
+					Length:           info.GetLength(),
+					InterfaceIndex:   info.GetInterfaceIndex(),
+					AncillaryData:    info.GetAncillaryData(),
+				},
+			}
+		}
+		return packets, nil
+	})
+	if err != nil {
+		return err
+	}
+	if rejected != nil {
+		return rejected
+	}
+	return srv.SendAndClose(&v1.CaptureSummary{
+		Packets: summary.GetPackets(),
+		Bytes:   summary.GetBytes(),
+		Files:   summary.GetFiles(),
+		Gaps:    summary.GetGaps(),
+		Missing: summary.GetMissing(),
+	})
+}
+
+// Control is the Control stream of the legacy service, for sessions that
+// agreed on it
+func (v *v1Server) Control(srv v1.RemoteCapture_ControlServer) error {
+	if err := v.require(srv.Context(), v1.Capability_CAPABILITY_CONTROL); err != nil {
+		return err
+	}
+	return v.s.Control(v1ControlStream{srv})
+}
+
+// v1ControlStream passes a v1 Control stream off as a legacy one, the command
+// types have the same numbers in both
+type v1ControlStream struct {
+	v1.RemoteCapture_ControlServer
+}
+
+func (c v1ControlStream) Send(cmd *service.Command) error {
+	return c.RemoteCapture_ControlServer.Send(&v1.Command{
+		Id:        cmd.GetID(),
+		Type:      v1.CommandType(cmd.GetType()),
+		Interface: cmd.GetInterface(),
+		Filter:    cmd.GetFilter(),
+	})
+}
+
+func (c v1ControlStream) Recv() (*service.CommandReply, error) {
+	r, err := c.RemoteCapture_ControlServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return &service.CommandReply{ID: r.GetId(), Ok: r.GetOk(), Error: r.GetError()}, nil
+}
+
+func (v *v1Server) ReportStats(ctx context.Context, stats *v1.CaptureStats) (*v1.ReportStatsResponse, error) {
+	if err := v.require(ctx, v1.Capability_CAPABILITY_STATS); err != nil {
+		return nil, err
+	}
+	_, err := v.s.ReportStats(ctx, &service.CaptureStats{
+		Interface:        stats.GetInterface(),
+		PacketsReceived:  stats.GetPacketsReceived(),
+		PacketsDropped:   stats.GetPacketsDropped(),
+		PacketsIfDropped: stats.GetPacketsIfDropped(),
+		Captured:         stats.GetCaptured(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ReportStatsResponse{}, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alwashali/gRPC-Remote-Traffic-Capture/service"
+	v1 "github.com/alwashali/gRPC-Remote-Traffic-Capture/service/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startV1 serves the v1 API of a server storing traces in a temporary dir
+func startV1(t *testing.T) v1.RemoteCaptureClient {
+	t.Helper()
+	cfg := defaultConfig()
+	cfg.Storage.Dir = t.TempDir()
+	s := &Server{endpoints: newRegistry(time.Minute, time.Hour), control: newControlHub(), cfg: cfg}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	v1.RegisterRemoteCaptureServer(gs, &v1Server{s: s})
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return v1.NewRemoteCaptureClient(conn)
+}
+
+// register opens a v1 session using capabilities and returns its context
+func register(t *testing.T, c v1.RemoteCaptureClient, capabilities ...v1.Capability) (context.Context, *v1.RegisterResponse) {
+	t.Helper()
+	r, err := c.Register(context.Background(), &v1.RegisterRequest{
+		ProtocolVersion: v1.ProtocolVersion + 1,
+		Capabilities:    capabilities,
+		Endpoint: &v1.Endpoint{
+			Hostname:  "h",
+			IpAddress: "192.0.2.1",
+			Interfaces: []*v1.CaptureInterface{
+				{Name: "eth0", LinkType: 1, Snaplen: 65535},
+				{Name: "eth1", LinkType: 1, Snaplen: 65535},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), service.SessionMetadataKey, r.GetSession().GetId()), r
+}
+
+func TestV1Register(t *testing.T) {
+	c := startV1(t)
+	if _, err := c.Register(context.Background(), &v1.RegisterRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("protocol version 0: %v", err)
+	}
+	_, r := register(t, c, v1.Capability_CAPABILITY_CONTROL, v1.Capability(99), v1.Capability_CAPABILITY_COMPRESSION)
+	if r.GetProtocolVersion() != v1.ProtocolVersion {
+		t.Errorf("protocol version %d, want %d", r.GetProtocolVersion(), v1.ProtocolVersion)
+	}
+	want := []v1.Capability{v1.Capability_CAPABILITY_CONTROL, v1.Capability_CAPABILITY_COMPRESSION}
+	if len(r.GetCapabilities()) != len(want) || r.GetCapabilities()[0] != want[0] || r.GetCapabilities()[1] != want[1] {
+		t.Errorf("agreed on %v, want %v", r.GetCapabilities(), want)
+	}
+	if len(r.GetSession().GetCompressors()) == 0 {
+		t.Error("no compressors offered with compression")
+	}
+	if _, r := register(t, c); len(r.GetSession().GetCompressors()) != 0 {
+		t.Errorf("compressors %v offered without compression", r.GetSession().GetCompressors())
+	}
+}
+
+func TestV1StreamPackets(t *testing.T) {
+	all := []v1.Capability{v1.Capability_CAPABILITY_SEQUENCE_NUMBERS, v1.Capability_CAPABILITY_MULTI_INTERFACE}
+	tests := []struct {
+		name         string
+		capabilities []v1.Capability
+		batches      []*v1.PacketBatch
+		code         codes.Code
+		// packets stored when the stream succeeds
+		packets uint64
+	}{
+		{
+			name:    "plain",
+			batches: []*v1.PacketBatch{{Packets: []*v1.Packet{v1Packet(0, 0), v1Packet(0, 0)}}},
+			packets: 2,
+		},
+		{
+			name:         "all capabilities",
+			capabilities: all,
+			batches: []*v1.PacketBatch{
+				{Sequence: 1, Packets: []*v1.Packet{v1Packet(1, 0), v1Packet(2, 1)}},
+				{Sequence: 2, Packets: []*v1.Packet{v1Packet(3, 1)}},
+			},
+			packets: 3,
+		},
+		{
+			name:    "sequence numbers not agreed",
+			batches: []*v1.PacketBatch{{Packets: []*v1.Packet{v1Packet(1, 0)}}},
+			code:    codes.FailedPrecondition,
+		},
+		{
+			name:    "batch sequence not agreed",
+			batches: []*v1.PacketBatch{{Sequence: 1, Packets: []*v1.Packet{v1Packet(0, 0)}}},
+			code:    codes.FailedPrecondition,
+		},
+		{
+			name:         "second interface not agreed",
+			capabilities: all[:1],
+			batches:      []*v1.PacketBatch{{Sequence: 1, Packets: []*v1.Packet{v1Packet(1, 1)}}},
+			code:         codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := startV1(t)
+			ctx, _ := register(t, c, tt.capabilities...)
+			stream, err := c.StreamPackets(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, batch := range tt.batches {
+				if err := stream.Send(batch); err != nil {
+					break
+				}
+			}
+			summary, err := stream.CloseAndRecv()
+			if status.Code(err) != tt.code {
+				t.Fatalf("stream ended with %v, want %v", err, tt.code)
+			}
+			if err == nil && (summary.GetPackets() != tt.packets || summary.GetGaps() != 0) {
+				t.Errorf("summary %v, want %d packets without gaps", summary, tt.packets)
+			}
+		})
+	}
+}
+
+func TestV1RequiresCapabilities(t *testing.T) {
+	c := startV1(t)
+	ctx, _ := register(t, c)
+	if _, err := c.ReportStats(ctx, &v1.CaptureStats{Interface: "eth0"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReportStats without stats: %v", err)
+	}
+	control, err := c.Control(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := control.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Control without control: %v", err)
+	}
+
+	ctx, _ = register(t, c, v1.Capability_CAPABILITY_STATS)
+	if _, err := c.ReportStats(ctx, &v1.CaptureStats{Interface: "eth0", PacketsReceived: 10}); err != nil {
+		t.Errorf("ReportStats with stats: %v", err)
+	}
+}
+
+func v1Packet(sequence uint64, intf int32) *v1.Packet {
+	data := []byte{1, 2, 3, 4}
+	return &v1.Packet{
+		Data:     data,
+		Sequence: sequence,
+		Info: &v1.CaptureInfo{
+			TimestampSeconds: time.Now().Unix(),
+			CaptureLength:    int64(len(data)),
+			Length:           int64(len(data)),
+			InterfaceIndex:   intf,
+		},
+	}
+}
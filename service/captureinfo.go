@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// NewCaptureInfo converts gopacket capture metadata into its wire form.
+// Ancillary data has no fixed type, each item is carried JSON encoded.
+func NewCaptureInfo(ci gopacket.CaptureInfo) *CaptureInfo {
+	info := &CaptureInfo{
+		TimestampSeconds: ci.Timestamp.Unix(),
+		TimestampNanos:   int32(ci.Timestamp.Nanosecond()),
+		CaptureLength:    int64(ci.CaptureLength),
+		Length:           int64(ci.Length),
+		InterfaceIndex:   int32(ci.InterfaceIndex),
+	}
+	for _, a := range ci.AncillaryData {
+		b, err := json.Marshal(a)
+		if err != nil {
+			continue
+		}
+		info.AncillaryData = append(info.AncillaryData, b)
+	}
+	return info
+}
+
+// GopacketCaptureInfo converts the wire form back into gopacket capture metadata.
+func (x *CaptureInfo) GopacketCaptureInfo() gopacket.CaptureInfo {
+	ci := gopacket.CaptureInfo{
+		Timestamp:      time.Unix(x.GetTimestampSeconds(), int64(x.GetTimestampNanos())),
+		CaptureLength:  int(x.GetCaptureLength()),
+		Length:         int(x.GetLength()),
+		InterfaceIndex: int(x.GetInterfaceIndex()),
+	}
+	for _, a := range x.GetAncillaryData() {
+		ci.AncillaryData = append(ci.AncillaryData, json.RawMessage(a))
+	}
+	return ci
+}
+
+// PacketCaptureInfo returns the capture metadata of a packet, preferring the
+// typed Info field and falling back to the legacy JSON encoding sent by old clients.
+func PacketCaptureInfo(pkt *Packet) (gopacket.CaptureInfo, error) {
+	if pkt.GetInfo() != nil {
+		return pkt.GetInfo().GopacketCaptureInfo(), nil
+	}
+	metadata := gopacket.PacketMetadata{}
+	err := json.Unmarshal(pkt.GetSeralizedcapturreinfo(), &metadata)
+	return metadata.CaptureInfo, err
+}
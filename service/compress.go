@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Names of the compressors Capture streams can be sent with. Collectors list
+// those they accept in ReadyReply.Compressors, CompressorNone disables
+// compression on an agent.
+const (
+	CompressorNone   = "none"
+	CompressorGzip   = gzip.Name
+	CompressorSnappy = "snappy"
+	CompressorZstd   = "zstd"
+)
+
+func init() {
+	encoding.RegisterCompressor(snappyCompressor{})
+	encoding.RegisterCompressor(zstdCompressor{})
+}
+
+// Compressors returns the compressors of this build, the preferred one first
+func Compressors() []string {
+	return []string{CompressorGzip, CompressorZstd, CompressorSnappy}
+}
+
+// CheckCompressor tells why name can't be used to compress Capture streams, nil if it can
+func CheckCompressor(name string) error {
+	switch name {
+	case CompressorGzip, CompressorSnappy, CompressorZstd:
+		return nil
+	}
+	return fmt.Errorf("unknown compressor %q", name)
+}
+
+// snappyCompressor is the snappy framing format as a gRPC compressor
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
+
+func (snappyCompressor) Name() string {
+	return CompressorSnappy
+}
+
+// maxZstdMessage bounds what a zstd compressed message may decompress to
+const maxZstdMessage = 64 << 20
+
+var (
+	// zstdEncoders are reused across messages, setting up an encoder costs
+	// more than compressing a batch of packets
+	zstdEncoders sync.Pool
+	// zstdDecoder decodes whole messages, which it can do concurrently
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxZstdMessage))
+)
+
+// zstdCompressor is zstd as a gRPC compressor
+type zstdCompressor struct{}
+
+func (zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if e, ok := zstdEncoders.Get().(*zstd.Encoder); ok {
+		e.Reset(w)
+		return &zstdWriter{e}, nil
+	}
+	e, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{e}, nil
+}
+
+func (zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	compressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := zstdDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (zstdCompressor) Name() string {
+	return CompressorZstd
+}
+
+// zstdWriter returns its encoder to the pool once the message is compressed
+type zstdWriter struct {
+	*zstd.Encoder
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	zstdEncoders.Put(w.Encoder)
+	return err
+}
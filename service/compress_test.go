@@ -0,0 +1,65 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCheckCompressor(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{CompressorGzip, true},
+		{CompressorSnappy, true},
+		{CompressorZstd, true},
+		{CompressorNone, false},
+		{"lz4", false},
+	}
+	for _, tt := range tests {
+		if err := CheckCompressor(tt.name); (err == nil) != tt.valid {
+			t.Errorf("CheckCompressor(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestCompressorsRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("packet data "), 1000)
+	for _, name := range Compressors() {
+		c := encoding.GetCompressor(name)
+		if c == nil {
+			t.Fatalf("%s is not registered", name)
+		}
+		// the second message may reuse what the first one set up
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			w, err := c.Compress(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.Len() >= len(data) {
+				t.Errorf("%s compressed %d bytes to %d", name, len(data), buf.Len())
+			}
+			r, err := c.Decompress(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("%s: got %d bytes back, want %d", name, len(got), len(data))
+			}
+		}
+	}
+}
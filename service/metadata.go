@@ -0,0 +1,9 @@
+package service
+
+// SessionMetadataKey is the gRPC metadata key a client sets on Capture
+// streams to the session ID returned by GetReady.
+const SessionMetadataKey = "session-id"
+
+// CredentialMetadataKey is the gRPC metadata key enrolled agents set on every
+// call to the credential the collector issued them.
+const CredentialMetadataKey = "agent-credential"
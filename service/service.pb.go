@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
+// 	protoc-gen-go v1.27.1
 // 	protoc        v3.13.0
 // source: service/service.proto
 
@@ -8,7 +8,6 @@ package service
 
 import (
 	context "context"
-	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -25,36 +24,877 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// This is a compile-time assertion that a sufficiently up-to-date version
-// of the legacy proto package is being used.
-const _ = proto.ProtoPackageIsVersion4
+// CaptureInfoFormat selects how packet capture metadata travels on the
+// Capture stream. Clients ask for a format in GetReady and the server answers
+// with the one it will accept; servers predating the negotiation always answer
+// LEGACY_JSON.
+type CaptureInfoFormat int32
+
+const (
+	CaptureInfoFormat_LEGACY_JSON CaptureInfoFormat = 0
+	CaptureInfoFormat_TYPED       CaptureInfoFormat = 1
+)
+
+// Enum value maps for CaptureInfoFormat.
+var (
+	CaptureInfoFormat_name = map[int32]string{
+		0: "LEGACY_JSON",
+		1: "TYPED",
+	}
+	CaptureInfoFormat_value = map[string]int32{
+		"LEGACY_JSON": 0,
+		"TYPED":       1,
+	}
+)
+
+func (x CaptureInfoFormat) Enum() *CaptureInfoFormat {
+	p := new(CaptureInfoFormat)
+	*p = x
+	return p
+}
+
+func (x CaptureInfoFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CaptureInfoFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_service_service_proto_enumTypes[0].Descriptor()
+}
+
+func (CaptureInfoFormat) Type() protoreflect.EnumType {
+	return &file_service_service_proto_enumTypes[0]
+}
+
+func (x CaptureInfoFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CaptureInfoFormat.Descriptor instead.
+func (CaptureInfoFormat) EnumDescriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{0}
+}
+
+// CommandType is what the server asks an agent to do on the Control stream
+type CommandType int32
+
+const (
+	CommandType_NOOP CommandType = 0
+	// start capturing on Interface with Filter, replacing any running capture
+	CommandType_START  CommandType = 1
+	CommandType_STOP   CommandType = 2
+	CommandType_PAUSE  CommandType = 3
+	CommandType_RESUME CommandType = 4
+	// replace the capture filter of the running capture with Filter
+	CommandType_SET_FILTER CommandType = 5
+)
+
+// Enum value maps for CommandType.
+var (
+	CommandType_name = map[int32]string{
+		0: "NOOP",
+		1: "START",
+		2: "STOP",
+		3: "PAUSE",
+		4: "RESUME",
+		5: "SET_FILTER",
+	}
+	CommandType_value = map[string]int32{
+		"NOOP":       0,
+		"START":      1,
+		"STOP":       2,
+		"PAUSE":      3,
+		"RESUME":     4,
+		"SET_FILTER": 5,
+	}
+)
+
+func (x CommandType) Enum() *CommandType {
+	p := new(CommandType)
+	*p = x
+	return p
+}
+
+func (x CommandType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CommandType) Descriptor() protoreflect.EnumDescriptor {
+	return file_service_service_proto_enumTypes[1].Descriptor()
+}
+
+func (CommandType) Type() protoreflect.EnumType {
+	return &file_service_service_proto_enumTypes[1]
+}
+
+func (x CommandType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CommandType.Descriptor instead.
+func (CommandType) EnumDescriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{1}
+}
+
+type CaptureInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TimestampSeconds int64    `protobuf:"varint,1,opt,name=TimestampSeconds,proto3" json:"TimestampSeconds,omitempty"`
+	TimestampNanos   int32    `protobuf:"varint,2,opt,name=TimestampNanos,proto3" json:"TimestampNanos,omitempty"`
+	CaptureLength    int64    `protobuf:"varint,3,opt,name=CaptureLength,proto3" json:"CaptureLength,omitempty"`
+	Length           int64    `protobuf:"varint,4,opt,name=Length,proto3" json:"Length,omitempty"`
+	InterfaceIndex   int32    `protobuf:"varint,5,opt,name=InterfaceIndex,proto3" json:"InterfaceIndex,omitempty"`
+	AncillaryData    [][]byte `protobuf:"bytes,6,rep,name=AncillaryData,proto3" json:"AncillaryData,omitempty"`
+}
+
+func (x *CaptureInfo) Reset() {
+	*x = CaptureInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaptureInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureInfo) ProtoMessage() {}
+
+func (x *CaptureInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureInfo.ProtoReflect.Descriptor instead.
+func (*CaptureInfo) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CaptureInfo) GetTimestampSeconds() int64 {
+	if x != nil {
+		return x.TimestampSeconds
+	}
+	return 0
+}
+
+func (x *CaptureInfo) GetTimestampNanos() int32 {
+	if x != nil {
+		return x.TimestampNanos
+	}
+	return 0
+}
+
+func (x *CaptureInfo) GetCaptureLength() int64 {
+	if x != nil {
+		return x.CaptureLength
+	}
+	return 0
+}
+
+func (x *CaptureInfo) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *CaptureInfo) GetInterfaceIndex() int32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *CaptureInfo) GetAncillaryData() [][]byte {
+	if x != nil {
+		return x.AncillaryData
+	}
+	return nil
+}
 
 type Packet struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data                  []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
-	Seralizedcapturreinfo []byte `protobuf:"bytes,2,opt,name=Seralizedcapturreinfo,proto3" json:"Seralizedcapturreinfo,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	// JSON encoded gopacket.PacketMetadata, only sent to LEGACY_JSON servers
+	Seralizedcapturreinfo []byte       `protobuf:"bytes,2,opt,name=Seralizedcapturreinfo,proto3" json:"Seralizedcapturreinfo,omitempty"`
+	Info                  *CaptureInfo `protobuf:"bytes,3,opt,name=Info,proto3" json:"Info,omitempty"`
+	// Sequence numbers the packets of a client from 1 in capture order, so
+	// the collector can tell lost and duplicate packets, 0 when unnumbered
+	Sequence uint64 `protobuf:"varint,4,opt,name=Sequence,proto3" json:"Sequence,omitempty"`
+}
+
+func (x *Packet) Reset() {
+	*x = Packet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Packet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Packet) ProtoMessage() {}
+
+func (x *Packet) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
+func (*Packet) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Packet) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Packet) GetSeralizedcapturreinfo() []byte {
+	if x != nil {
+		return x.Seralizedcapturreinfo
+	}
+	return nil
+}
+
+func (x *Packet) GetInfo() *CaptureInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *Packet) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// PacketBatch carries several packets in one Capture frame, Sequence counts
+// batches sent on the stream starting at 1.
+type PacketBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sequence uint64    `protobuf:"varint,1,opt,name=Sequence,proto3" json:"Sequence,omitempty"`
+	Packets  []*Packet `protobuf:"bytes,2,rep,name=Packets,proto3" json:"Packets,omitempty"`
+}
+
+func (x *PacketBatch) Reset() {
+	*x = PacketBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PacketBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PacketBatch) ProtoMessage() {}
+
+func (x *PacketBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PacketBatch.ProtoReflect.Descriptor instead.
+func (*PacketBatch) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PacketBatch) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *PacketBatch) GetPackets() []*Packet {
+	if x != nil {
+		return x.Packets
+	}
+	return nil
+}
+
+// EndpointInfo registers a client with GetReady. A registered client calls
+// GetReady again under its session whenever it starts another capture.
+type EndpointInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hostname          string            `protobuf:"bytes,1,opt,name=Hostname,proto3" json:"Hostname,omitempty"`
+	IPaddress         string            `protobuf:"bytes,2,opt,name=IPaddress,proto3" json:"IPaddress,omitempty"`
+	Interface         string            `protobuf:"bytes,3,opt,name=Interface,proto3" json:"Interface,omitempty"`
+	CaptureInfoFormat CaptureInfoFormat `protobuf:"varint,4,opt,name=CaptureInfoFormat,proto3,enum=service.CaptureInfoFormat" json:"CaptureInfoFormat,omitempty"`
+	Batching          bool              `protobuf:"varint,5,opt,name=Batching,proto3" json:"Batching,omitempty"`
+	// LinkType and Snaplen of the capture on Interface, the pcap header of
+	// its traces. Both are unknown while Snaplen is 0.
+	LinkType int32  `protobuf:"varint,6,opt,name=LinkType,proto3" json:"LinkType,omitempty"`
+	Snaplen  uint32 `protobuf:"varint,7,opt,name=Snaplen,proto3" json:"Snaplen,omitempty"`
+	// OS of the client and the BPF filter of the capture, recorded in pcapng traces
+	OS     string `protobuf:"bytes,8,opt,name=OS,proto3" json:"OS,omitempty"`
+	Filter string `protobuf:"bytes,9,opt,name=Filter,proto3" json:"Filter,omitempty"`
+	// Interfaces of the capture, packets refer to them by their index here
+	// in CaptureInfo.InterfaceIndex. The first one is also described by the
+	// fields above for servers that don't know about several interfaces.
+	Interfaces []*CaptureInterface `protobuf:"bytes,10,rep,name=Interfaces,proto3" json:"Interfaces,omitempty"`
+	// EnrollmentToken is exchanged for an agent credential by collectors
+	// requiring enrollment, agents already holding one send it as
+	// agent-credential metadata instead
+	EnrollmentToken string `protobuf:"bytes,11,opt,name=EnrollmentToken,proto3" json:"EnrollmentToken,omitempty"`
+	// Instance identifies the client process numbering the packets. A new
+	// session of the same instance goes on with the sequence of the last
+	// one, so packets lost across reconnects are found.
+	Instance string `protobuf:"bytes,12,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *EndpointInfo) Reset() {
+	*x = EndpointInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointInfo) ProtoMessage() {}
+
+func (x *EndpointInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointInfo.ProtoReflect.Descriptor instead.
+func (*EndpointInfo) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EndpointInfo) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetIPaddress() string {
+	if x != nil {
+		return x.IPaddress
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetCaptureInfoFormat() CaptureInfoFormat {
+	if x != nil {
+		return x.CaptureInfoFormat
+	}
+	return CaptureInfoFormat_LEGACY_JSON
+}
+
+func (x *EndpointInfo) GetBatching() bool {
+	if x != nil {
+		return x.Batching
+	}
+	return false
+}
+
+func (x *EndpointInfo) GetLinkType() int32 {
+	if x != nil {
+		return x.LinkType
+	}
+	return 0
+}
+
+func (x *EndpointInfo) GetSnaplen() uint32 {
+	if x != nil {
+		return x.Snaplen
+	}
+	return 0
+}
+
+func (x *EndpointInfo) GetOS() string {
+	if x != nil {
+		return x.OS
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetInterfaces() []*CaptureInterface {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+func (x *EndpointInfo) GetEnrollmentToken() string {
+	if x != nil {
+		return x.EnrollmentToken
+	}
+	return ""
+}
+
+func (x *EndpointInfo) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+type CaptureInterface struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	LinkType int32  `protobuf:"varint,2,opt,name=LinkType,proto3" json:"LinkType,omitempty"`
+	Snaplen  uint32 `protobuf:"varint,3,opt,name=Snaplen,proto3" json:"Snaplen,omitempty"`
+	Filter   string `protobuf:"bytes,4,opt,name=Filter,proto3" json:"Filter,omitempty"`
+}
+
+func (x *CaptureInterface) Reset() {
+	*x = CaptureInterface{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaptureInterface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureInterface) ProtoMessage() {}
+
+func (x *CaptureInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureInterface.ProtoReflect.Descriptor instead.
+func (*CaptureInterface) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CaptureInterface) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CaptureInterface) GetLinkType() int32 {
+	if x != nil {
+		return x.LinkType
+	}
+	return 0
+}
+
+func (x *CaptureInterface) GetSnaplen() uint32 {
+	if x != nil {
+		return x.Snaplen
+	}
+	return 0
+}
+
+func (x *CaptureInterface) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+// ReadyReply describes the session GetReady opened or described a capture of
+type ReadyReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Okay              string            `protobuf:"bytes,1,opt,name=okay,proto3" json:"okay,omitempty"`
+	CaptureInfoFormat CaptureInfoFormat `protobuf:"varint,2,opt,name=CaptureInfoFormat,proto3,enum=service.CaptureInfoFormat" json:"CaptureInfoFormat,omitempty"`
+	Batching          bool              `protobuf:"varint,3,opt,name=Batching,proto3" json:"Batching,omitempty"`
+	// sent back as session-id metadata on Capture and CaptureBatch
+	SessionID string `protobuf:"bytes,4,opt,name=SessionID,proto3" json:"SessionID,omitempty"`
+	// Credential is issued once, in exchange for an enrollment token. It is
+	// sent as agent-credential metadata on every later call.
+	Credential string `protobuf:"bytes,5,opt,name=Credential,proto3" json:"Credential,omitempty"`
+	// Compressors the server accepts Capture streams compressed with, in
+	// order of preference. Streams are sent uncompressed when it is empty.
+	Compressors []string `protobuf:"bytes,6,rep,name=Compressors,proto3" json:"Compressors,omitempty"`
+	// ServerVersion is the version of the collector
+	ServerVersion string `protobuf:"bytes,7,opt,name=ServerVersion,proto3" json:"ServerVersion,omitempty"`
+	// Snaplen is the most bytes of a packet the collector stores, longer
+	// packets are truncated
+	Snaplen uint32 `protobuf:"varint,8,opt,name=Snaplen,proto3" json:"Snaplen,omitempty"`
+	// MaxRate is the most packet bytes per second the client may send, 0 for no limit
+	MaxRate uint64 `protobuf:"varint,9,opt,name=MaxRate,proto3" json:"MaxRate,omitempty"`
+	// RequiredFilters are BPF filters the client must capture with, on top
+	// of its own filter
+	RequiredFilters []string `protobuf:"bytes,10,rep,name=RequiredFilters,proto3" json:"RequiredFilters,omitempty"`
+}
+
+func (x *ReadyReply) Reset() {
+	*x = ReadyReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadyReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyReply) ProtoMessage() {}
+
+func (x *ReadyReply) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyReply.ProtoReflect.Descriptor instead.
+func (*ReadyReply) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReadyReply) GetOkay() string {
+	if x != nil {
+		return x.Okay
+	}
+	return ""
+}
+
+func (x *ReadyReply) GetCaptureInfoFormat() CaptureInfoFormat {
+	if x != nil {
+		return x.CaptureInfoFormat
+	}
+	return CaptureInfoFormat_LEGACY_JSON
+}
+
+func (x *ReadyReply) GetBatching() bool {
+	if x != nil {
+		return x.Batching
+	}
+	return false
+}
+
+func (x *ReadyReply) GetSessionID() string {
+	if x != nil {
+		return x.SessionID
+	}
+	return ""
+}
+
+func (x *ReadyReply) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+func (x *ReadyReply) GetCompressors() []string {
+	if x != nil {
+		return x.Compressors
+	}
+	return nil
+}
+
+func (x *ReadyReply) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+func (x *ReadyReply) GetSnaplen() uint32 {
+	if x != nil {
+		return x.Snaplen
+	}
+	return 0
+}
+
+func (x *ReadyReply) GetMaxRate() uint64 {
+	if x != nil {
+		return x.MaxRate
+	}
+	return 0
+}
+
+func (x *ReadyReply) GetRequiredFilters() []string {
+	if x != nil {
+		return x.RequiredFilters
+	}
+	return nil
+}
+
+// CaptureSummary is what the collector stored of a Capture or CaptureBatch stream
+type CaptureSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Okay    string `protobuf:"bytes,1,opt,name=okay,proto3" json:"okay,omitempty"`
+	Packets uint64 `protobuf:"varint,2,opt,name=Packets,proto3" json:"Packets,omitempty"`
+	Bytes   uint64 `protobuf:"varint,3,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	// Files are the traces written, as named on the collector
+	Files []string `protobuf:"bytes,4,rep,name=Files,proto3" json:"Files,omitempty"`
+	// Gaps counts the ranges of sequence numbers that went missing, Missing
+	// the packets in them
+	Gaps    uint64 `protobuf:"varint,5,opt,name=Gaps,proto3" json:"Gaps,omitempty"`
+	Missing uint64 `protobuf:"varint,6,opt,name=Missing,proto3" json:"Missing,omitempty"`
+}
+
+func (x *CaptureSummary) Reset() {
+	*x = CaptureSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaptureSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureSummary) ProtoMessage() {}
+
+func (x *CaptureSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureSummary.ProtoReflect.Descriptor instead.
+func (*CaptureSummary) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CaptureSummary) GetOkay() string {
+	if x != nil {
+		return x.Okay
+	}
+	return ""
+}
+
+func (x *CaptureSummary) GetPackets() uint64 {
+	if x != nil {
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *CaptureSummary) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *CaptureSummary) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *CaptureSummary) GetGaps() uint64 {
+	if x != nil {
+		return x.Gaps
+	}
+	return 0
+}
+
+func (x *CaptureSummary) GetMissing() uint64 {
+	if x != nil {
+		return x.Missing
+	}
+	return 0
+}
+
+type Command struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID        uint64      `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Type      CommandType `protobuf:"varint,2,opt,name=Type,proto3,enum=service.CommandType" json:"Type,omitempty"`
+	Interface string      `protobuf:"bytes,3,opt,name=Interface,proto3" json:"Interface,omitempty"`
+	Filter    string      `protobuf:"bytes,4,opt,name=Filter,proto3" json:"Filter,omitempty"`
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_service_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Command) GetID() uint64 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Command) GetType() CommandType {
+	if x != nil {
+		return x.Type
+	}
+	return CommandType_NOOP
+}
+
+func (x *Command) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *Command) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+// CommandReply acknowledges the Command with the same ID, agents report
+// problems outside of a command, such as a capture failing, with ID 0.
+type CommandReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID    uint64 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Ok    bool   `protobuf:"varint,2,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error string `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
 }
 
-func (x *Packet) Reset() {
-	*x = Packet{}
+func (x *CommandReply) Reset() {
+	*x = CommandReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_service_service_proto_msgTypes[0]
+		mi := &file_service_service_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Packet) String() string {
+func (x *CommandReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Packet) ProtoMessage() {}
+func (*CommandReply) ProtoMessage() {}
 
-func (x *Packet) ProtoReflect() protoreflect.Message {
-	mi := &file_service_service_proto_msgTypes[0]
+func (x *CommandReply) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -65,52 +905,64 @@ func (x *Packet) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
-func (*Packet) Descriptor() ([]byte, []int) {
-	return file_service_service_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use CommandReply.ProtoReflect.Descriptor instead.
+func (*CommandReply) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *Packet) GetData() []byte {
+func (x *CommandReply) GetID() uint64 {
 	if x != nil {
-		return x.Data
+		return x.ID
 	}
-	return nil
+	return 0
 }
 
-func (x *Packet) GetSeralizedcapturreinfo() []byte {
+func (x *CommandReply) GetOk() bool {
 	if x != nil {
-		return x.Seralizedcapturreinfo
+		return x.Ok
 	}
-	return nil
+	return false
 }
 
-type EndpointInfo struct {
+func (x *CommandReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// CaptureStats are the libpcap counters of the capture on Interface, they
+// count from the start of that capture. Captured counts the packets the
+// client forwarded to the collector.
+type CaptureStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hostname  string `protobuf:"bytes,1,opt,name=Hostname,proto3" json:"Hostname,omitempty"`
-	IPaddress string `protobuf:"bytes,2,opt,name=IPaddress,proto3" json:"IPaddress,omitempty"`
-	Interface string `protobuf:"bytes,3,opt,name=Interface,proto3" json:"Interface,omitempty"`
+	Interface        string `protobuf:"bytes,1,opt,name=Interface,proto3" json:"Interface,omitempty"`
+	PacketsReceived  int64  `protobuf:"varint,2,opt,name=PacketsReceived,proto3" json:"PacketsReceived,omitempty"`
+	PacketsDropped   int64  `protobuf:"varint,3,opt,name=PacketsDropped,proto3" json:"PacketsDropped,omitempty"`
+	PacketsIfDropped int64  `protobuf:"varint,4,opt,name=PacketsIfDropped,proto3" json:"PacketsIfDropped,omitempty"`
+	Captured         int64  `protobuf:"varint,5,opt,name=Captured,proto3" json:"Captured,omitempty"`
 }
 
-func (x *EndpointInfo) Reset() {
-	*x = EndpointInfo{}
+func (x *CaptureStats) Reset() {
+	*x = CaptureStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_service_service_proto_msgTypes[1]
+		mi := &file_service_service_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *EndpointInfo) String() string {
+func (x *CaptureStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EndpointInfo) ProtoMessage() {}
+func (*CaptureStats) ProtoMessage() {}
 
-func (x *EndpointInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_service_service_proto_msgTypes[1]
+func (x *CaptureStats) ProtoReflect() protoreflect.Message {
+	mi := &file_service_service_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -121,30 +973,44 @@ func (x *EndpointInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EndpointInfo.ProtoReflect.Descriptor instead.
-func (*EndpointInfo) Descriptor() ([]byte, []int) {
-	return file_service_service_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use CaptureStats.ProtoReflect.Descriptor instead.
+func (*CaptureStats) Descriptor() ([]byte, []int) {
+	return file_service_service_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *EndpointInfo) GetHostname() string {
+func (x *CaptureStats) GetInterface() string {
 	if x != nil {
-		return x.Hostname
+		return x.Interface
 	}
 	return ""
 }
 
-func (x *EndpointInfo) GetIPaddress() string {
+func (x *CaptureStats) GetPacketsReceived() int64 {
 	if x != nil {
-		return x.IPaddress
+		return x.PacketsReceived
 	}
-	return ""
+	return 0
 }
 
-func (x *EndpointInfo) GetInterface() string {
+func (x *CaptureStats) GetPacketsDropped() int64 {
 	if x != nil {
-		return x.Interface
+		return x.PacketsDropped
 	}
-	return ""
+	return 0
+}
+
+func (x *CaptureStats) GetPacketsIfDropped() int64 {
+	if x != nil {
+		return x.PacketsIfDropped
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetCaptured() int64 {
+	if x != nil {
+		return x.Captured
+	}
+	return 0
 }
 
 type Empty struct {
@@ -158,7 +1024,7 @@ type Empty struct {
 func (x *Empty) Reset() {
 	*x = Empty{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_service_service_proto_msgTypes[2]
+		mi := &file_service_service_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -171,7 +1037,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_service_service_proto_msgTypes[2]
+	mi := &file_service_service_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -184,7 +1050,7 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_service_service_proto_rawDescGZIP(), []int{2}
+	return file_service_service_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Empty) GetOkay() string {
@@ -199,29 +1065,159 @@ var File_service_service_proto protoreflect.FileDescriptor
 var file_service_service_proto_rawDesc = []byte{
 	0x0a, 0x15, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
 	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x22, 0x52, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61,
-	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x34,
-	0x0a, 0x15, 0x53, 0x65, 0x72, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x63, 0x61, 0x70, 0x74, 0x75,
-	0x72, 0x72, 0x65, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x15, 0x53,
-	0x65, 0x72, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x72, 0x65,
-	0x69, 0x6e, 0x66, 0x6f, 0x22, 0x66, 0x0a, 0x0c, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74,
-	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
-	0x12, 0x1c, 0x0a, 0x09, 0x49, 0x50, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x49, 0x50, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1c,
-	0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x22, 0x1b, 0x0a, 0x05,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x32, 0x74, 0x0a, 0x0d, 0x52, 0x65, 0x6d,
-	0x6f, 0x74, 0x65, 0x43, 0x61, 0x70, 0x75, 0x74, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x43, 0x61,
-	0x70, 0x74, 0x75, 0x72, 0x65, 0x12, 0x0f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
-	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x33, 0x0a, 0x08, 0x47, 0x65,
-	0x74, 0x52, 0x65, 0x61, 0x64, 0x79, 0x12, 0x15, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x0e, 0x2e,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42,
-	0x12, 0x5a, 0x10, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x3b, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x22, 0xed, 0x01, 0x0a, 0x0b, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x12, 0x2a, 0x0a, 0x10, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x26, 0x0a, 0x0e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4e,
+	0x61, 0x6e, 0x6f, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x4c,
+	0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x43, 0x61, 0x70,
+	0x74, 0x75, 0x72, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x4c, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x12, 0x26, 0x0a, 0x0e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x66, 0x61, 0x63, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x24, 0x0a, 0x0d, 0x41, 0x6e,
+	0x63, 0x69, 0x6c, 0x6c, 0x61, 0x72, 0x79, 0x44, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0c, 0x52, 0x0d, 0x41, 0x6e, 0x63, 0x69, 0x6c, 0x6c, 0x61, 0x72, 0x79, 0x44, 0x61, 0x74, 0x61,
+	0x22, 0x98, 0x01, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x44,
+	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x34, 0x0a, 0x15, 0x53, 0x65, 0x72, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x63, 0x61, 0x70, 0x74,
+	0x75, 0x72, 0x72, 0x65, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x15,
+	0x53, 0x65, 0x72, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x72,
+	0x65, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x28, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x61,
+	0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x1a, 0x0a, 0x08, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x54, 0x0a, 0x0b, 0x50,
+	0x61, 0x63, 0x6b, 0x65, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x53, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x53, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x07, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x07, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x73, 0x22, 0xab, 0x03, 0x0a, 0x0c, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x49, 0x50, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x49, 0x50, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09,
+	0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x48, 0x0a, 0x11, 0x43, 0x61,
+	0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x52, 0x11, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67,
+	0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x4c, 0x69, 0x6e, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x53, 0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x53,
+	0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x4f, 0x53, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x4f, 0x53, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x39,
+	0x0a, 0x0a, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x61, 0x70,
+	0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x52, 0x0a, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x45, 0x6e, 0x72,
+	0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0f, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22,
+	0x74, 0x0a, 0x10, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66,
+	0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x6b, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x4c, 0x69, 0x6e, 0x6b, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x53, 0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x46,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0xea, 0x02, 0x0a, 0x0a, 0x52, 0x65, 0x61, 0x64, 0x79, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x12, 0x48, 0x0a, 0x11, 0x43, 0x61, 0x70, 0x74,
+	0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x61,
+	0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52,
+	0x11, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x1c,
+	0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a,
+	0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x20, 0x0a, 0x0b,
+	0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x24,
+	0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x53, 0x6e, 0x61, 0x70, 0x6c, 0x65, 0x6e, 0x12, 0x18,
+	0x0a, 0x07, 0x4d, 0x61, 0x78, 0x52, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x07, 0x4d, 0x61, 0x78, 0x52, 0x61, 0x74, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x52, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0f, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x73, 0x22, 0x98, 0x01, 0x0a, 0x0e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x75,
+	0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x50, 0x61, 0x63,
+	0x6b, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x50, 0x61, 0x63, 0x6b,
+	0x65, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x46, 0x69, 0x6c,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x47, 0x61, 0x70, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x47,
+	0x61, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x22, 0x79, 0x0a,
+	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x28, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x44, 0x0a, 0x0c, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x0e, 0x0a, 0x02, 0x4f, 0x6b, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x4f, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xc6,
+	0x01, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12,
+	0x1c, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x28, 0x0a,
+	0x0f, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x50, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12,
+	0x2a, 0x0a, 0x10, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x49, 0x66, 0x44, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x50, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x49, 0x66, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x43,
+	0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x43,
+	0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x22, 0x1b, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x6f, 0x6b, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6f, 0x6b, 0x61, 0x79, 0x2a, 0x2f, 0x0a, 0x11, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x0f, 0x0a, 0x0b, 0x4c, 0x45, 0x47,
+	0x41, 0x43, 0x59, 0x5f, 0x4a, 0x53, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x54, 0x59,
+	0x50, 0x45, 0x44, 0x10, 0x01, 0x2a, 0x53, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4f, 0x50, 0x10, 0x00, 0x12, 0x09,
+	0x0a, 0x05, 0x53, 0x54, 0x41, 0x52, 0x54, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f,
+	0x50, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x50, 0x41, 0x55, 0x53, 0x45, 0x10, 0x03, 0x12, 0x0a,
+	0x0a, 0x06, 0x52, 0x45, 0x53, 0x55, 0x4d, 0x45, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x45,
+	0x54, 0x5f, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x10, 0x05, 0x32, 0xb7, 0x02, 0x0a, 0x0d, 0x52,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x61, 0x70, 0x75, 0x74, 0x72, 0x65, 0x12, 0x37, 0x0a, 0x07,
+	0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x12, 0x0f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x17, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x41, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x14, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x17, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x38, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x61, 0x64, 0x79, 0x12, 0x15, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x45,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x13, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x38, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x15, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x1a, 0x10, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x0b,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x15, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x1a, 0x0e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x22, 0x00, 0x42, 0x12, 0x5a, 0x10, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
+	0x3b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -236,22 +1232,45 @@ func file_service_service_proto_rawDescGZIP() []byte {
 	return file_service_service_proto_rawDescData
 }
 
-var file_service_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_service_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_service_service_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_service_service_proto_goTypes = []interface{}{
-	(*Packet)(nil),       // 0: service.Packet
-	(*EndpointInfo)(nil), // 1: service.EndpointInfo
-	(*Empty)(nil),        // 2: service.Empty
+	(CaptureInfoFormat)(0),   // 0: service.CaptureInfoFormat
+	(CommandType)(0),         // 1: service.CommandType
+	(*CaptureInfo)(nil),      // 2: service.CaptureInfo
+	(*Packet)(nil),           // 3: service.Packet
+	(*PacketBatch)(nil),      // 4: service.PacketBatch
+	(*EndpointInfo)(nil),     // 5: service.EndpointInfo
+	(*CaptureInterface)(nil), // 6: service.CaptureInterface
+	(*ReadyReply)(nil),       // 7: service.ReadyReply
+	(*CaptureSummary)(nil),   // 8: service.CaptureSummary
+	(*Command)(nil),          // 9: service.Command
+	(*CommandReply)(nil),     // 10: service.CommandReply
+	(*CaptureStats)(nil),     // 11: service.CaptureStats
+	(*Empty)(nil),            // 12: service.Empty
 }
 var file_service_service_proto_depIdxs = []int32{
-	0, // 0: service.RemoteCaputre.Capture:input_type -> service.Packet
-	1, // 1: service.RemoteCaputre.GetReady:input_type -> service.EndpointInfo
-	2, // 2: service.RemoteCaputre.Capture:output_type -> service.Empty
-	2, // 3: service.RemoteCaputre.GetReady:output_type -> service.Empty
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: service.Packet.Info:type_name -> service.CaptureInfo
+	3,  // 1: service.PacketBatch.Packets:type_name -> service.Packet
+	0,  // 2: service.EndpointInfo.CaptureInfoFormat:type_name -> service.CaptureInfoFormat
+	6,  // 3: service.EndpointInfo.Interfaces:type_name -> service.CaptureInterface
+	0,  // 4: service.ReadyReply.CaptureInfoFormat:type_name -> service.CaptureInfoFormat
+	1,  // 5: service.Command.Type:type_name -> service.CommandType
+	3,  // 6: service.RemoteCaputre.Capture:input_type -> service.Packet
+	4,  // 7: service.RemoteCaputre.CaptureBatch:input_type -> service.PacketBatch
+	5,  // 8: service.RemoteCaputre.GetReady:input_type -> service.EndpointInfo
+	10, // 9: service.RemoteCaputre.Control:input_type -> service.CommandReply
+	11, // 10: service.RemoteCaputre.ReportStats:input_type -> service.CaptureStats
+	8,  // 11: service.RemoteCaputre.Capture:output_type -> service.CaptureSummary
+	8,  // 12: service.RemoteCaputre.CaptureBatch:output_type -> service.CaptureSummary
+	7,  // 13: service.RemoteCaputre.GetReady:output_type -> service.ReadyReply
+	9,  // 14: service.RemoteCaputre.Control:output_type -> service.Command
+	12, // 15: service.RemoteCaputre.ReportStats:output_type -> service.Empty
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_service_service_proto_init() }
@@ -261,7 +1280,7 @@ func file_service_service_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_service_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Packet); i {
+			switch v := v.(*CaptureInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -273,7 +1292,7 @@ func file_service_service_proto_init() {
 			}
 		}
 		file_service_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EndpointInfo); i {
+			switch v := v.(*Packet); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -285,6 +1304,102 @@ func file_service_service_proto_init() {
 			}
 		}
 		file_service_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PacketBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CaptureInterface); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadyReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CaptureSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CaptureStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Empty); i {
 			case 0:
 				return &v.state
@@ -302,13 +1417,14 @@ func file_service_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_service_service_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_service_service_proto_goTypes,
 		DependencyIndexes: file_service_service_proto_depIdxs,
+		EnumInfos:         file_service_service_proto_enumTypes,
 		MessageInfos:      file_service_service_proto_msgTypes,
 	}.Build()
 	File_service_service_proto = out.File
@@ -330,7 +1446,10 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type RemoteCaputreClient interface {
 	Capture(ctx context.Context, opts ...grpc.CallOption) (RemoteCaputre_CaptureClient, error)
-	GetReady(ctx context.Context, in *EndpointInfo, opts ...grpc.CallOption) (*Empty, error)
+	CaptureBatch(ctx context.Context, opts ...grpc.CallOption) (RemoteCaputre_CaptureBatchClient, error)
+	GetReady(ctx context.Context, in *EndpointInfo, opts ...grpc.CallOption) (*ReadyReply, error)
+	Control(ctx context.Context, opts ...grpc.CallOption) (RemoteCaputre_ControlClient, error)
+	ReportStats(ctx context.Context, in *CaptureStats, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type remoteCaputreClient struct {
@@ -352,7 +1471,7 @@ func (c *remoteCaputreClient) Capture(ctx context.Context, opts ...grpc.CallOpti
 
 type RemoteCaputre_CaptureClient interface {
 	Send(*Packet) error
-	CloseAndRecv() (*Empty, error)
+	CloseAndRecv() (*CaptureSummary, error)
 	grpc.ClientStream
 }
 
@@ -364,19 +1483,53 @@ func (x *remoteCaputreCaptureClient) Send(m *Packet) error {
 	return x.ClientStream.SendMsg(m)
 }
 
-func (x *remoteCaputreCaptureClient) CloseAndRecv() (*Empty, error) {
+func (x *remoteCaputreCaptureClient) CloseAndRecv() (*CaptureSummary, error) {
 	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
 	}
-	m := new(Empty)
+	m := new(CaptureSummary)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *remoteCaputreClient) GetReady(ctx context.Context, in *EndpointInfo, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *remoteCaputreClient) CaptureBatch(ctx context.Context, opts ...grpc.CallOption) (RemoteCaputre_CaptureBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RemoteCaputre_serviceDesc.Streams[1], "/service.RemoteCaputre/CaptureBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteCaputreCaptureBatchClient{stream}
+	return x, nil
+}
+
+type RemoteCaputre_CaptureBatchClient interface {
+	Send(*PacketBatch) error
+	CloseAndRecv() (*CaptureSummary, error)
+	grpc.ClientStream
+}
+
+type remoteCaputreCaptureBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteCaputreCaptureBatchClient) Send(m *PacketBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *remoteCaputreCaptureBatchClient) CloseAndRecv() (*CaptureSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(CaptureSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *remoteCaputreClient) GetReady(ctx context.Context, in *EndpointInfo, opts ...grpc.CallOption) (*ReadyReply, error) {
+	out := new(ReadyReply)
 	err := c.cc.Invoke(ctx, "/service.RemoteCaputre/GetReady", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -384,10 +1537,53 @@ func (c *remoteCaputreClient) GetReady(ctx context.Context, in *EndpointInfo, op
 	return out, nil
 }
 
+func (c *remoteCaputreClient) Control(ctx context.Context, opts ...grpc.CallOption) (RemoteCaputre_ControlClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RemoteCaputre_serviceDesc.Streams[2], "/service.RemoteCaputre/Control", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteCaputreControlClient{stream}
+	return x, nil
+}
+
+type RemoteCaputre_ControlClient interface {
+	Send(*CommandReply) error
+	Recv() (*Command, error)
+	grpc.ClientStream
+}
+
+type remoteCaputreControlClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteCaputreControlClient) Send(m *CommandReply) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *remoteCaputreControlClient) Recv() (*Command, error) {
+	m := new(Command)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *remoteCaputreClient) ReportStats(ctx context.Context, in *CaptureStats, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/service.RemoteCaputre/ReportStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RemoteCaputreServer is the server API for RemoteCaputre service.
 type RemoteCaputreServer interface {
 	Capture(RemoteCaputre_CaptureServer) error
-	GetReady(context.Context, *EndpointInfo) (*Empty, error)
+	CaptureBatch(RemoteCaputre_CaptureBatchServer) error
+	GetReady(context.Context, *EndpointInfo) (*ReadyReply, error)
+	Control(RemoteCaputre_ControlServer) error
+	ReportStats(context.Context, *CaptureStats) (*Empty, error)
 }
 
 // UnimplementedRemoteCaputreServer can be embedded to have forward compatible implementations.
@@ -397,9 +1593,18 @@ type UnimplementedRemoteCaputreServer struct {
 func (*UnimplementedRemoteCaputreServer) Capture(RemoteCaputre_CaptureServer) error {
 	return status.Errorf(codes.Unimplemented, "method Capture not implemented")
 }
-func (*UnimplementedRemoteCaputreServer) GetReady(context.Context, *EndpointInfo) (*Empty, error) {
+func (*UnimplementedRemoteCaputreServer) CaptureBatch(RemoteCaputre_CaptureBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method CaptureBatch not implemented")
+}
+func (*UnimplementedRemoteCaputreServer) GetReady(context.Context, *EndpointInfo) (*ReadyReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetReady not implemented")
 }
+func (*UnimplementedRemoteCaputreServer) Control(RemoteCaputre_ControlServer) error {
+	return status.Errorf(codes.Unimplemented, "method Control not implemented")
+}
+func (*UnimplementedRemoteCaputreServer) ReportStats(context.Context, *CaptureStats) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportStats not implemented")
+}
 
 func RegisterRemoteCaputreServer(s *grpc.Server, srv RemoteCaputreServer) {
 	s.RegisterService(&_RemoteCaputre_serviceDesc, srv)
@@ -410,7 +1615,7 @@ func _RemoteCaputre_Capture_Handler(srv interface{}, stream grpc.ServerStream) e
 }
 
 type RemoteCaputre_CaptureServer interface {
-	SendAndClose(*Empty) error
+	SendAndClose(*CaptureSummary) error
 	Recv() (*Packet, error)
 	grpc.ServerStream
 }
@@ -419,7 +1624,7 @@ type remoteCaputreCaptureServer struct {
 	grpc.ServerStream
 }
 
-func (x *remoteCaputreCaptureServer) SendAndClose(m *Empty) error {
+func (x *remoteCaputreCaptureServer) SendAndClose(m *CaptureSummary) error {
 	return x.ServerStream.SendMsg(m)
 }
 
@@ -431,6 +1636,32 @@ func (x *remoteCaputreCaptureServer) Recv() (*Packet, error) {
 	return m, nil
 }
 
+func _RemoteCaputre_CaptureBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RemoteCaputreServer).CaptureBatch(&remoteCaputreCaptureBatchServer{stream})
+}
+
+type RemoteCaputre_CaptureBatchServer interface {
+	SendAndClose(*CaptureSummary) error
+	Recv() (*PacketBatch, error)
+	grpc.ServerStream
+}
+
+type remoteCaputreCaptureBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *remoteCaputreCaptureBatchServer) SendAndClose(m *CaptureSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *remoteCaputreCaptureBatchServer) Recv() (*PacketBatch, error) {
+	m := new(PacketBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _RemoteCaputre_GetReady_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EndpointInfo)
 	if err := dec(in); err != nil {
@@ -449,6 +1680,50 @@ func _RemoteCaputre_GetReady_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RemoteCaputre_Control_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RemoteCaputreServer).Control(&remoteCaputreControlServer{stream})
+}
+
+type RemoteCaputre_ControlServer interface {
+	Send(*Command) error
+	Recv() (*CommandReply, error)
+	grpc.ServerStream
+}
+
+type remoteCaputreControlServer struct {
+	grpc.ServerStream
+}
+
+func (x *remoteCaputreControlServer) Send(m *Command) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *remoteCaputreControlServer) Recv() (*CommandReply, error) {
+	m := new(CommandReply)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RemoteCaputre_ReportStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CaptureStats)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteCaputreServer).ReportStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.RemoteCaputre/ReportStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteCaputreServer).ReportStats(ctx, req.(*CaptureStats))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RemoteCaputre_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "service.RemoteCaputre",
 	HandlerType: (*RemoteCaputreServer)(nil),
@@ -457,6 +1732,10 @@ var _RemoteCaputre_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetReady",
 			Handler:    _RemoteCaputre_GetReady_Handler,
 		},
+		{
+			MethodName: "ReportStats",
+			Handler:    _RemoteCaputre_ReportStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -464,6 +1743,17 @@ var _RemoteCaputre_serviceDesc = grpc.ServiceDesc{
 			Handler:       _RemoteCaputre_Capture_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "CaptureBatch",
+			Handler:       _RemoteCaputre_CaptureBatch_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Control",
+			Handler:       _RemoteCaputre_Control_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "service/service.proto",
 }
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadCertPool reads the PEM encoded CA certificates in path
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,4 @@
+package v1
+
+// ProtocolVersion is the version of the protocol this package speaks
+const ProtocolVersion = 1